@@ -0,0 +1,83 @@
+package client
+
+// Recording of mutating operations in the local audit log, and optionally on
+// the server. Requests opt in by describing the operation in their audit field.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/audit"
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Command is the command line recorded with each operation, set by the cmd package
+var Command string
+
+// operation describes a mutating request for the audit log
+type operation struct {
+	action   string // e.g. "delete workload"
+	resource string // e.g. "prod-eks/cedana/train-42"
+}
+
+// recordOperation appends the outcome of an operation to the audit log, and
+// ships it to the server if enabled. Failing to record never fails the
+// operation itself, but is warned about.
+func recordOperation(ctx context.Context, op *operation, status int, opErr error) {
+	if !config.Global.Audit.Enabled {
+		return
+	}
+
+	result := "ok"
+	switch {
+	case opErr != nil:
+		result = opErr.Error()
+	case status < 200 || status > 299:
+		result = fmt.Sprintf("failed with status %d", status)
+	}
+	host, _ := os.Hostname()
+	entry := audit.Entry{
+		Time:      time.Now(),
+		User:      audit.CurrentUser(),
+		Host:      host,
+		ConfigDir: config.Dir,
+		URL:       config.Global.Connection.URL,
+		Command:   Command,
+		Action:    op.action,
+		Resource:  op.resource,
+		Result:    result,
+		Status:    status,
+	}
+
+	if err := audit.Append(config.Dir, entry); err != nil {
+		log.Warn().Err(err).Str("action", op.action).Msg("failed to record operation in audit log")
+		fmt.Fprintf(WarningOutput, "Warning: failed to record %s in audit log: %v\n", op.action, err)
+	}
+
+	if config.Global.Audit.Ship {
+		resp, err := clientRequest(ctx, request{method: "POST", path: "/audit", body: entry})
+		if err != nil {
+			log.Warn().Err(err).Str("action", op.action).Msg("failed to ship audit record to server")
+			fmt.Fprintf(WarningOutput, "Warning: failed to ship audit record of %s to server: %v\n", op.action, err)
+			return
+		}
+		resp.Body.Close()
+	}
+}
+
+// manifestResource returns the resource a workload payload targets, e.g.
+// "prod-eks/cedana/train-42", or "unknown" if the payload can't be decoded
+func manifestResource(payload []byte, ct ContentType) string {
+	manifest, err := decodeManifest(payload, ct)
+	if err != nil {
+		return "unknown"
+	}
+	return workloadResource(manifest.ClusterName, manifest.Workload.Metadata.Namespace, manifest.Workload.Metadata.Name)
+}
+
+func workloadResource(clusterName, namespace, name string) string {
+	return clusterName + "/" + namespace + "/" + name
+}
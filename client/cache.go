@@ -0,0 +1,203 @@
+package client
+
+// On-disk cache of listings that rarely change, such as clusters, nodes and
+// namespaces, so shell completion doesn't wait on the server. Completions use
+// entries as is until their TTL expires. Listings shown to users always ask
+// the server, revalidating the entry with the ETag it returned, if any, so
+// statuses are never out of date.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+const CACHE_FILE_PERM = 0o600 // listings may be sensitive, keep them private
+
+type allowStaleKey struct{}
+
+// AllowStale lets listings requested with the returned context be served from
+// the cache without asking the server, until their TTL expires. This suits
+// lookups of names, such as shell completion, but not listings shown to users.
+func AllowStale(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowStaleKey{}, true)
+}
+
+func staleAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allowStaleKey{}).(bool)
+	return allowed
+}
+
+type cacheEntry struct {
+	StoredAt    time.Time `json:"stored_at"`
+	ETag        string    `json:"etag,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body"`
+}
+
+// CacheDir returns the directory cached responses are stored in
+func CacheDir() string {
+	return filepath.Join(config.Dir, config.CACHE_DIR_NAME)
+}
+
+// ClearCache removes all cached responses, returning how many there were
+func ClearCache() (int, error) {
+	entries, err := os.ReadDir(CacheDir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".json") {
+			count++
+		}
+	}
+	return count, os.RemoveAll(CacheDir())
+}
+
+// cacheKey identifies a request by everything that affects its response,
+// including the auth token so different accounts never share entries
+func cacheKey(req *http.Request, payload []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		req.Method,
+		req.URL.String(),
+		req.Header.Get("Accept"),
+		req.Header.Get("Authorization"),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func cachePath(key string) string {
+	return filepath.Join(CacheDir(), key+".json")
+}
+
+func loadCacheEntry(key string) *cacheEntry {
+	data, err := os.ReadFile(cachePath(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Debug().Err(err).Str("key", key).Msg("ignoring corrupt cache entry")
+		return nil
+	}
+	return &entry
+}
+
+// storeCacheEntry writes the entry atomically, so concurrent invocations
+// never read a partial entry. Failures are only logged, as caching is best-effort.
+func storeCacheEntry(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal cache entry")
+		return
+	}
+	if err := os.MkdirAll(CacheDir(), config.DIR_PERM); err != nil {
+		log.Debug().Err(err).Msg("failed to create cache directory")
+		return
+	}
+	tmp, err := os.CreateTemp(CacheDir(), key+".*.tmp")
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to write cache entry")
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		log.Debug().Err(err).Msg("failed to write cache entry")
+		return
+	}
+	tmp.Close()
+	os.Chmod(tmp.Name(), CACHE_FILE_PERM)
+	if err := os.Rename(tmp.Name(), cachePath(key)); err != nil {
+		log.Debug().Err(err).Msg("failed to write cache entry")
+	}
+}
+
+func (e *cacheEntry) fresh() bool {
+	return time.Since(e.StoredAt) < config.Global.Cache.TTL
+}
+
+// response returns the cached entry as a response to req
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cachedDo sends req, revalidating any cached entry with If-None-Match and
+// caching successful responses. If the context of req allows stale responses,
+// a fresh entry is used without sending req.
+func cachedDo(req *http.Request, payload []byte) (*http.Response, error) {
+	key := cacheKey(req, payload)
+	entry := loadCacheEntry(key)
+	if entry != nil && entry.fresh() && staleAllowed(req.Context()) {
+		log.Debug().Str("url", req.URL.String()).Msg("using cached response")
+		return entry.response(req), nil
+	}
+	if entry != nil && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		log.Debug().Str("url", req.URL.String()).Msg("cached response still valid")
+		entry.StoredAt = time.Now()
+		storeCacheEntry(key, entry)
+		return entry.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	storeCacheEntry(key, &cacheEntry{
+		StoredAt:    time.Now(),
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	})
+	return resp, nil
+}
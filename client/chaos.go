@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// InterruptNodes makes a POST request asking the platform to simulate a
+// spot-termination notice on the given nodes of a cluster
+func InterruptNodes(ctx context.Context, clusterName string, nodeNames []string) (*Interruption, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/chaos/interrupt",
+		body: map[string]any{
+			"cluster_name": clusterName,
+			"nodes":        nodeNames,
+		},
+		audit: &operation{
+			action:   "interrupt nodes",
+			resource: clusterName + "/" + strings.Join(nodeNames, ","),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error interrupting nodes: %v", err)
+	}
+	var interruption Interruption
+	if err := decodeResponse(resp, &interruption); err != nil {
+		return nil, err
+	}
+	return &interruption, nil
+}
+
+// GetInterruption makes a POST request to fetch the progress of a simulated interruption
+func GetInterruption(ctx context.Context, clusterName string, id string) (*Interruption, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/chaos/interrupt/" + id,
+		body:   map[string]string{"cluster_name": clusterName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching interruption: %v", err)
+	}
+	var interruption Interruption
+	if err := decodeResponse(resp, &interruption); err != nil {
+		return nil, err
+	}
+	return &interruption, nil
+}
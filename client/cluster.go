@@ -1,24 +1,15 @@
 package client
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-
-	"github.com/cedana/cedana-cli/pkg/config"
 )
 
-// ListClusters makes a GET request to fetch all clusters
-func ListClusters() ([]Cluster, error) {
-	cedanaURL := config.Global.Connection.URL
-	cedanaAuthToken := config.Global.Connection.AuthToken
-	var clusters []Cluster
-	resp, err := clientRequest("GET", cedanaURL+"/cluster", cedanaAuthToken, nil)
+// ListClusters makes a GET request to fetch the clusters matching opts
+func ListClusters(ctx context.Context, opts ListOptions) (*Page[Cluster], error) {
+	clusters, err := list(ctx, request{method: "GET", path: "/cluster", cache: true}, opts, ClusterFields)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
-	}
-	defer resp.Body.Close()
-	if err := json.NewDecoder(resp.Body).Decode(&clusters); err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
+		return nil, fmt.Errorf("error fetching clusters: %v", err)
 	}
 	return clusters, nil
 }
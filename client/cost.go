@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetCosts makes a POST request to fetch cost records between since and until.
+// If clusterName is empty, records for all clusters of the organization are returned.
+func GetCosts(ctx context.Context, clusterName string, since time.Time, until time.Time) ([]CostRecord, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cost",
+		body: map[string]string{
+			"cluster_name": clusterName,
+			"since":        since.UTC().Format(time.RFC3339),
+			"until":        until.UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching costs: %v", err)
+	}
+	var records []CostRecord
+	if err := decodeResponse(resp, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,198 @@
+package client
+
+// Forward-compatible decoding of API responses. Fields unknown to this CLI are
+// ignored by default, or rejected in strict mode so API changes get noticed.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// API_VERSION is the version of the API schema known to this CLI
+	API_VERSION = "1.0"
+
+	API_VERSION_HEADER = "X-Cedana-API-Version"
+)
+
+var apiVersionWarning sync.Once
+
+// WarningOutput is where warnings meant for the user, rather than the logs, are written
+var WarningOutput io.Writer = os.Stderr
+
+// checkAPIVersion warns, once per invocation, if the server speaks a newer API
+// version than this CLI knows about
+func checkAPIVersion(resp *http.Response) {
+	serverVersion := resp.Header.Get(API_VERSION_HEADER)
+	if serverVersion == "" {
+		return
+	}
+	newer, err := NewerVersion(serverVersion, API_VERSION)
+	if err != nil {
+		log.Debug().Err(err).Str("version", serverVersion).Msg("ignoring invalid API version header")
+		return
+	}
+	if !newer {
+		return
+	}
+	apiVersionWarning.Do(func() {
+		log.Warn().Str("server", serverVersion).Str("cli", API_VERSION).Msg("server API is newer than CLI")
+		fmt.Fprintf(WarningOutput,
+			"Warning: server API version %s is newer than this CLI's (%s), some fields may be missing. Consider upgrading cedana-cli.\n",
+			serverVersion, API_VERSION,
+		)
+	})
+}
+
+// NewerVersion reports whether version a is newer than b. Versions are
+// dot-separated numbers with an optional "v" prefix, e.g. "v1.2".
+func NewerVersion(a, b string) (bool, error) {
+	partsA, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	partsB, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			return x > y, nil
+		}
+	}
+	return false, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// unmarshalJSON decodes data into v, checking for fields unknown to v
+func unmarshalJSON(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	return checkUnknownFields(data, v)
+}
+
+// checkUnknownFields looks for fields in a JSON body that v has no place for.
+// In strict mode they are returned as an error, otherwise only logged.
+func checkUnknownFields(data []byte, v any) error {
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil // already reported by the actual decode
+	}
+
+	found := make(map[string]bool)
+	unknownFields(generic, reflect.TypeOf(v), "", found)
+	if len(found) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(found))
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	if config.Global.Trace.StrictDecode {
+		return fmt.Errorf("response has fields unknown to this CLI: %s", strings.Join(fields, ", "))
+	}
+	log.Debug().Strs("fields", fields).Str("type", reflect.TypeOf(v).String()).Msg("ignoring unknown response fields")
+	return nil
+}
+
+// unknownFields walks a generic JSON value alongside the type it is decoded
+// into, recording the paths of object keys the type has no field for
+func unknownFields(data any, t reflect.Type, path string, found map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := data.(map[string]any)
+		if !ok {
+			return // e.g. time.Time, which is decoded from a string
+		}
+		fields := jsonFields(t)
+		for key, value := range object {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				found[joinPath(path, key)] = true
+				continue
+			}
+			unknownFields(value, field, joinPath(path, key), found)
+		}
+	case reflect.Slice, reflect.Array:
+		array, ok := data.([]any)
+		if !ok {
+			return
+		}
+		for _, value := range array {
+			unknownFields(value, t.Elem(), path+"[]", found)
+		}
+	case reflect.Map:
+		object, ok := data.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, value := range object {
+			unknownFields(value, t.Elem(), joinPath(path, key), found)
+		}
+	}
+}
+
+// jsonFields returns the types of a struct's fields keyed by their lowercased
+// JSON name, as encoding/json matches keys case-insensitively
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
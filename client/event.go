@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetEvents makes a POST request to fetch the events of an object of the
+// given kind (Node, Pod or Workload) in a cluster. Namespace is ignored for nodes.
+func GetEvents(ctx context.Context, clusterName, namespace, kind, name string) ([]Event, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/events",
+		body: map[string]any{
+			"cluster_name": clusterName,
+			"namespace":    namespace,
+			"kind":         kind,
+			"name":         name,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching events: %v", err)
+	}
+	var events []Event
+	if err := decodeResponse(resp, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,285 @@
+package client
+
+// Pagination, filtering and sorting shared by list endpoints. Options are sent
+// to the server as query parameters and applied again client-side, so they
+// also work with servers that do not support them.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListOptions filter, sort and page through the results of a list endpoint
+type ListOptions struct {
+	// Limit is the maximum number of items to return, 0 for all
+	Limit int
+	// Continue is the token returned with a previous limited list, to fetch the
+	// items that follow
+	Continue string
+	// Selector is a comma-separated list of label requirements, e.g.
+	// "app=gromacs,tier!=batch". A bare key requires the label to exist.
+	Selector string
+	// FieldSelector is a comma-separated list of field requirements, e.g. "status=Running"
+	FieldSelector string
+	// SortBy is the field to sort by
+	SortBy string
+}
+
+// Page is the result of a list call. Continue is set when there are more
+// items to fetch, and can be passed back through ListOptions. Truncated is set
+// instead when items were cut off to honor the limit, e.g. by a server that
+// ignores it, since continuing after such a page would skip them.
+type Page[T any] struct {
+	Items     []T    `json:"Items"`
+	Continue  string `json:"Continue"`
+	Truncated bool   `json:"Truncated,omitempty"`
+}
+
+// listFields describes how a listed type is filtered and sorted client-side
+type listFields[T any] struct {
+	labels  func(T) map[string]string
+	fields  map[string]func(T) string
+	sorters map[string]func(a, b T) bool
+}
+
+// FieldNames returns the sorted names of the fields usable in a field selector
+func (f listFields[T]) FieldNames() []string {
+	return sortedKeys(f.fields)
+}
+
+// SortNames returns the sorted names of the fields usable to sort by
+func (f listFields[T]) SortNames() []string {
+	return sortedKeys(f.sorters)
+}
+
+// requirement is a single term of a selector
+type requirement struct {
+	key    string
+	value  string
+	negate bool
+	exists bool // only the presence of the key matters
+}
+
+// parseSelector parses a comma-separated list of key=value, key!=value, key
+// or !key terms. Bare keys are only allowed if allowExists is set.
+func parseSelector(selector string, allowExists bool) ([]requirement, error) {
+	var requirements []requirement
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		var r requirement
+		if key, value, ok := strings.Cut(term, "!="); ok {
+			r = requirement{key: key, value: value, negate: true}
+		} else if key, value, ok := strings.Cut(term, "="); ok {
+			r = requirement{key: key, value: strings.TrimPrefix(value, "=")}
+		} else if allowExists {
+			key, negate := strings.CutPrefix(term, "!")
+			r = requirement{key: key, negate: negate, exists: true}
+		} else {
+			return nil, fmt.Errorf("invalid selector term %q, expected key=value or key!=value", term)
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if r.key == "" {
+			return nil, fmt.Errorf("invalid selector term %q, missing key", term)
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, nil
+}
+
+func (r requirement) matches(value string, present bool) bool {
+	if r.exists {
+		return present != r.negate
+	}
+	return (present && value == r.value) != r.negate
+}
+
+// list fetches all pages of a list endpoint, or just enough to satisfy the
+// limit, and applies the options to the items
+func list[T any](ctx context.Context, r request, opts ListOptions, f listFields[T]) (*Page[T], error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("limit must not be negative")
+	}
+	labelRequirements, err := parseSelector(opts.Selector, true)
+	if err != nil {
+		return nil, err
+	}
+	fieldRequirements, err := parseSelector(opts.FieldSelector, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range fieldRequirements {
+		if _, ok := f.fields[r.key]; !ok {
+			return nil, fmt.Errorf("unknown field %q in field selector, must be one of: %s", r.key, strings.Join(f.FieldNames(), ", "))
+		}
+	}
+	less := f.sorters[opts.SortBy]
+	if opts.SortBy != "" && less == nil {
+		return nil, fmt.Errorf("invalid sort field %q, must be one of: %s", opts.SortBy, strings.Join(f.SortNames(), ", "))
+	}
+
+	match := func(item T) bool {
+		labels := f.labels(item)
+		for _, r := range labelRequirements {
+			value, present := labels[r.key]
+			if !r.matches(value, present) {
+				return false
+			}
+		}
+		for _, r := range fieldRequirements {
+			if !r.matches(f.fields[r.key](item), true) {
+				return false
+			}
+		}
+		return true
+	}
+
+	path := r.path
+	result := &Page[T]{}
+	token := opts.Continue
+	paginated := false
+
+	for {
+		query := url.Values{}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit-len(result.Items)))
+		}
+		if token != "" {
+			query.Set("continue", token)
+		}
+		if opts.Selector != "" {
+			query.Set("labelSelector", opts.Selector)
+		}
+		if opts.FieldSelector != "" {
+			query.Set("fieldSelector", opts.FieldSelector)
+		}
+		if opts.SortBy != "" {
+			query.Set("sortBy", opts.SortBy)
+		}
+		r.path = path
+		if len(query) > 0 {
+			r.path += "?" + query.Encode()
+		}
+
+		resp, err := clientRequest(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := decodeResponse(resp, &raw); err != nil {
+			return nil, err
+		}
+
+		// Servers without pagination respond with a plain array of all items
+		var page Page[T]
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if token != "" {
+				return nil, fmt.Errorf("server does not support continuing a list")
+			}
+			err = unmarshalJSON(raw, &page.Items)
+		} else {
+			paginated = true
+			err = unmarshalJSON(raw, &page)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding response: %v", err)
+		}
+
+		for _, item := range page.Items {
+			if match(item) {
+				result.Items = append(result.Items, item)
+			}
+		}
+
+		token = page.Continue
+		if !paginated || token == "" || (opts.Limit > 0 && len(result.Items) >= opts.Limit) {
+			break
+		}
+	}
+
+	if less != nil {
+		sort.SliceStable(result.Items, func(i, j int) bool { return less(result.Items[i], result.Items[j]) })
+	}
+	if opts.Limit > 0 && len(result.Items) > opts.Limit {
+		result.Items = result.Items[:opts.Limit]
+		result.Truncated = true
+	}
+	if paginated && !result.Truncated {
+		result.Continue = token
+	}
+	return result, nil
+}
+
+////////////////////
+///// Fields ///////
+////////////////////
+
+var ClusterFields = listFields[Cluster]{
+	labels: func(c Cluster) map[string]string { return c.Labels },
+	fields: map[string]func(c Cluster) string{
+		"name":     func(c Cluster) string { return c.Name },
+		"status":   func(c Cluster) string { return c.Status },
+		"provider": func(c Cluster) string { return c.Metadata.Provider },
+		"region":   func(c Cluster) string { return c.Metadata.Region },
+	},
+	sorters: map[string]func(a, b Cluster) bool{
+		"name":    func(a, b Cluster) bool { return a.Name < b.Name },
+		"status":  func(a, b Cluster) bool { return a.Status < b.Status },
+		"created": func(a, b Cluster) bool { return a.CreatedAt.Before(b.CreatedAt) },
+		"updated": func(a, b Cluster) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+	},
+}
+
+var NodeFields = listFields[Node]{
+	labels: func(n Node) map[string]string { return n.Labels },
+	fields: map[string]func(n Node) string{
+		"name":          func(n Node) string { return n.Name },
+		"status":        func(n Node) string { return n.Status },
+		"compute-type":  func(n Node) string { return n.ComputeType },
+		"instance-type": func(n Node) string { return n.InstanceType },
+		"region":        func(n Node) string { return n.Region },
+	},
+	sorters: map[string]func(a, b Node) bool{
+		"name":          func(a, b Node) bool { return a.Name < b.Name },
+		"status":        func(a, b Node) bool { return a.Status < b.Status },
+		"instance-type": func(a, b Node) bool { return a.InstanceType < b.InstanceType },
+		"created":       func(a, b Node) bool { return a.CreatedAt.Before(b.CreatedAt) },
+		"updated":       func(a, b Node) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+	},
+}
+
+var PodFields = listFields[Pod]{
+	labels: func(p Pod) map[string]string { return p.Labels },
+	fields: map[string]func(p Pod) string{
+		"name":      func(p Pod) string { return p.Name },
+		"namespace": func(p Pod) string { return p.Namespace },
+		"status":    func(p Pod) string { return p.Status },
+		"node":      func(p Pod) string { return p.NodeID },
+		"owner":     func(p Pod) string { return p.Metadata.Owner },
+	},
+	sorters: map[string]func(a, b Pod) bool{
+		"name":     func(a, b Pod) bool { return a.Name < b.Name },
+		"status":   func(a, b Pod) bool { return a.Status < b.Status },
+		"restarts": func(a, b Pod) bool { return a.Metadata.RestartCount > b.Metadata.RestartCount },
+		"created":  func(a, b Pod) bool { return a.CreatedAt.Before(b.CreatedAt) },
+		"updated":  func(a, b Pod) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+	},
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
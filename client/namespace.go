@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListNamespaces makes a POST request to fetch the names of the namespaces of a given cluster
+func ListNamespaces(ctx context.Context, clusterName string) ([]string, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/namespaces",
+		body:   map[string]string{"cluster_name": clusterName},
+		cache:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching namespaces: %v", err)
+	}
+	var namespaces []string
+	if err := decodeResponse(resp, &namespaces); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
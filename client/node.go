@@ -1,35 +1,20 @@
 package client
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-
-	"github.com/cedana/cedana-cli/pkg/config"
 )
 
-// GetClusterNodes makes a POST request to fetch nodes for a given cluster
-func GetClusterNodes(clusterName string) ([]Node, error) {
-	cedanaURL := config.Global.Connection.URL
-	cedanaAuthToken := config.Global.Connection.AuthToken
-
-	payload := map[string]string{
-		"cluster_name": clusterName,
-	}
-
-	jsonData, err := json.Marshal(payload)
+// GetClusterNodes makes a POST request to fetch the nodes of a given cluster matching opts
+func GetClusterNodes(ctx context.Context, clusterName string, opts ListOptions) (*Page[Node], error) {
+	nodes, err := list(ctx, request{
+		method: "POST",
+		path:   "/cluster/nodes",
+		body:   map[string]string{"cluster_name": clusterName},
+		cache:  true,
+	}, opts, NodeFields)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling payload: %v", err)
+		return nil, fmt.Errorf("error fetching nodes: %v", err)
 	}
-
-	resp, err := clientRequest("POST", cedanaURL+"/cluster/nodes", cedanaAuthToken, jsonData)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
-	}
-	defer resp.Body.Close()
-	var nodes []Node
-	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
-	}
-
 	return nodes, nil
 }
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetClusterNodePools makes a POST request to fetch nodepools for a given cluster
+func GetClusterNodePools(ctx context.Context, clusterName string) ([]NodePool, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/nodepools",
+		body:   map[string]string{"cluster_name": clusterName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching nodepools: %v", err)
+	}
+	var nodePools []NodePool
+	if err := decodeResponse(resp, &nodePools); err != nil {
+		return nil, err
+	}
+	return nodePools, nil
+}
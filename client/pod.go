@@ -1,35 +1,72 @@
 package client
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-
-	"github.com/cedana/cedana-cli/pkg/config"
 )
 
-// GetClusterNodes makes a POST request to fetch nodes for a given cluster
-func GetClusterPods(clusterName string, clusterNamespace string) ([]Pod, error) {
-	cedanaURL := config.Global.Connection.URL
-	cedanaAuthToken := config.Global.Connection.AuthToken
-  
-	payload := map[string]string{
-		"cluster_name": clusterName,
-	}
-  
-	jsonData, err := json.Marshal(payload)
+// GetClusterPods makes a POST request to fetch the pods under given namespace
+// of a cluster matching opts
+func GetClusterPods(ctx context.Context, clusterName string, clusterNamespace string, opts ListOptions) (*Page[Pod], error) {
+	pods, err := list(ctx, request{
+		method: "POST",
+		path:   "/cluster/pods/" + clusterNamespace,
+		body:   map[string]string{"cluster_name": clusterName},
+		cache:  true,
+	}, opts, PodFields)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling payload: %v", err)
+		return nil, fmt.Errorf("error fetching pods: %v", err)
 	}
-  
-	resp, err := clientRequest("POST", cedanaURL+"/cluster/pods/"+clusterNamespace, cedanaAuthToken, jsonData)
+	return pods, nil
+}
+
+// GetPodLogs makes a POST request to fetch the last tailLines lines of logs of
+// a pod, or all of them if tailLines is 0
+func GetPodLogs(ctx context.Context, clusterName, namespace, name string, tailLines int) (string, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/pods/logs",
+		body: map[string]any{
+			"cluster_name": clusterName,
+			"namespace":    namespace,
+			"name":         name,
+			"tail_lines":   tailLines,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
+		return "", fmt.Errorf("error fetching logs: %v", err)
 	}
-	defer resp.Body.Close()
-	var pods []Pod
-	if err := json.NewDecoder(resp.Body).Decode(&pods); err != nil {
-		return nil, fmt.Errorf("error decoding response: %v", err)
+	return readResponse(resp)
+}
+
+// CheckpointPod makes a POST request to checkpoint a running pod, leaving it running
+func CheckpointPod(ctx context.Context, clusterName, namespace, name string) (string, error) {
+	payload := map[string]any{
+		"cluster_name": clusterName,
+		"namespace":    namespace,
+		"name":         name,
 	}
+	return workloadAction(ctx, "POST", "/cluster/pod/checkpoint", payload, &operation{
+		action:   "checkpoint pod",
+		resource: workloadResource(clusterName, namespace, name),
+	})
+}
 
-	return pods, nil
+// MigratePod makes a POST request to checkpoint a pod and restore it on
+// another node. If targetNode is empty, the platform picks the node.
+func MigratePod(ctx context.Context, clusterName, namespace, name, targetNode string) (string, error) {
+	payload := map[string]any{
+		"cluster_name": clusterName,
+		"namespace":    namespace,
+		"name":         name,
+		"target_node":  targetNode,
+	}
+	action := "migrate pod"
+	if targetNode != "" {
+		action += " to " + targetNode
+	}
+	return workloadAction(ctx, "POST", "/cluster/pod/migrate", payload, &operation{
+		action:   action,
+		resource: workloadResource(clusterName, namespace, name),
+	})
 }
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetClusterQueues makes a POST request to fetch kueue cluster queues for a given cluster
+func GetClusterQueues(ctx context.Context, clusterName string) ([]ClusterQueue, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/queues",
+		body:   map[string]string{"cluster_name": clusterName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching queues: %v", err)
+	}
+	var queues []ClusterQueue
+	if err := decodeResponse(resp, &queues); err != nil {
+		return nil, err
+	}
+	return queues, nil
+}
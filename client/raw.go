@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RawResponse is the unprocessed response to a raw API call
+type RawResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// OK returns whether the response has a 2xx status
+func (r *RawResponse) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode <= 299
+}
+
+// Raw sends a request to an arbitrary path of the configured endpoint, for
+// endpoints the CLI has no command for. The body, if any, is sent as is with
+// the given content type. Unlike other calls, non-2xx responses are returned
+// rather than turned into errors, so their body can be shown.
+func Raw(ctx context.Context, method, path string, body []byte, contentType ContentType, header http.Header) (*RawResponse, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	r := request{
+		method:      strings.ToUpper(method),
+		path:        path,
+		contentType: contentType,
+		header:      header,
+		keepErrors:  true,
+	}
+	if body != nil {
+		r.body = body
+	}
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		r.audit = &operation{action: "api " + r.method, resource: path}
+	}
+
+	resp, err := clientRequest(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s %s: %v", r.method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+	return &RawResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       data,
+	}, nil
+}
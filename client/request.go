@@ -0,0 +1,324 @@
+package client
+
+// Single request builder for all API calls. Bodies can be encoded as JSON,
+// YAML or protobuf, the latter being used with the cedana buf-generated types.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// ContentType is the encoding of a request or response body
+type ContentType string
+
+const (
+	CONTENT_TYPE_JSON     ContentType = "json"
+	CONTENT_TYPE_YAML     ContentType = "yaml"
+	CONTENT_TYPE_PROTOBUF ContentType = "protobuf"
+
+	// Request bodies larger than this are gzip-compressed
+	GZIP_MIN_SIZE = 64 << 10
+)
+
+var contentTypeMIMEs = map[ContentType]string{
+	CONTENT_TYPE_JSON:     "application/json",
+	CONTENT_TYPE_YAML:     "application/yaml",
+	CONTENT_TYPE_PROTOBUF: "application/x-protobuf",
+}
+
+var contentTypeAliases = map[string]ContentType{
+	"json":                   CONTENT_TYPE_JSON,
+	"application/json":       CONTENT_TYPE_JSON,
+	"yaml":                   CONTENT_TYPE_YAML,
+	"yml":                    CONTENT_TYPE_YAML,
+	"application/yaml":       CONTENT_TYPE_YAML,
+	"application/x-yaml":     CONTENT_TYPE_YAML,
+	"text/yaml":              CONTENT_TYPE_YAML,
+	"protobuf":               CONTENT_TYPE_PROTOBUF,
+	"proto":                  CONTENT_TYPE_PROTOBUF,
+	"pb":                     CONTENT_TYPE_PROTOBUF,
+	"binpb":                  CONTENT_TYPE_PROTOBUF,
+	"application/x-protobuf": CONTENT_TYPE_PROTOBUF,
+	"application/protobuf":   CONTENT_TYPE_PROTOBUF,
+}
+
+// ParseContentType parses a content type given by name (json, yaml, protobuf)
+// or MIME type. An empty string defaults to JSON.
+func ParseContentType(s string) (ContentType, error) {
+	if s == "" {
+		return CONTENT_TYPE_JSON, nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(s); err == nil {
+		s = mediaType
+	}
+	if ct, ok := contentTypeAliases[strings.ToLower(s)]; ok {
+		return ct, nil
+	}
+	return "", fmt.Errorf("unsupported content type %q, must be one of json, yaml or protobuf", s)
+}
+
+// ContentTypeFromPath infers the content type of a file from its extension,
+// defaulting to JSON
+func ContentTypeFromPath(path string) ContentType {
+	if ct, ok := contentTypeAliases[strings.TrimPrefix(filepath.Ext(path), ".")]; ok {
+		return ct
+	}
+	return CONTENT_TYPE_JSON
+}
+
+func (c ContentType) MIME() string {
+	return contentTypeMIMEs[c]
+}
+
+// Marshal encodes v. Protobuf requires v to be a proto.Message, while JSON and
+// YAML use protojson for proto messages so field names match the API.
+func (c ContentType) Marshal(v any) ([]byte, error) {
+	msg, isProto := v.(proto.Message)
+	switch c {
+	case CONTENT_TYPE_JSON:
+		if isProto {
+			return protojson.Marshal(msg)
+		}
+		return json.Marshal(v)
+	case CONTENT_TYPE_YAML:
+		if isProto {
+			data, err := protojson.Marshal(msg)
+			if err != nil {
+				return nil, err
+			}
+			var generic any
+			if err := json.Unmarshal(data, &generic); err != nil {
+				return nil, err
+			}
+			v = generic
+		}
+		return yaml.Marshal(v)
+	case CONTENT_TYPE_PROTOBUF:
+		if !isProto {
+			return nil, fmt.Errorf("cannot encode %T as protobuf", v)
+		}
+		return proto.Marshal(msg)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", c)
+	}
+}
+
+// Unmarshal decodes data into v, see Marshal
+func (c ContentType) Unmarshal(data []byte, v any) error {
+	msg, isProto := v.(proto.Message)
+	switch c {
+	case CONTENT_TYPE_JSON:
+		if isProto {
+			return protojson.Unmarshal(data, msg)
+		}
+		return json.Unmarshal(data, v)
+	case CONTENT_TYPE_YAML:
+		if isProto {
+			var generic any
+			if err := yaml.Unmarshal(data, &generic); err != nil {
+				return err
+			}
+			jsonData, err := json.Marshal(generic)
+			if err != nil {
+				return err
+			}
+			return protojson.Unmarshal(jsonData, msg)
+		}
+		return yaml.Unmarshal(data, v)
+	case CONTENT_TYPE_PROTOBUF:
+		if !isProto {
+			return fmt.Errorf("cannot decode protobuf into %T", v)
+		}
+		return proto.Unmarshal(data, msg)
+	default:
+		return fmt.Errorf("unsupported content type %q", c)
+	}
+}
+
+// request describes a single call to the configured Cedana endpoint
+type request struct {
+	method string
+	path   string // relative to the configured URL
+	// body is encoded as contentType, unless it is already []byte
+	body        any
+	contentType ContentType // defaults to JSON
+	accept      ContentType // defaults to JSON
+	// cache allows the response to be served from, and stored in, the local cache
+	cache bool
+	// header holds extra headers, overriding the defaults
+	header http.Header
+	// keepErrors returns non-2xx responses as is, instead of turning them into errors
+	keepErrors bool
+	// audit records the request in the audit log as a mutating operation. Reads
+	// are also sent as POST, so only audited requests are treated as mutating.
+	audit *operation
+}
+
+// clientRequest sends the request with the configured auth token. Any
+// non-2xx response is turned into an error, unless keepErrors is set.
+func clientRequest(ctx context.Context, r request) (resp *http.Response, err error) {
+	if r.audit != nil {
+		defer func() {
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode
+			} else if statusErr, ok := err.(*statusError); ok {
+				status = statusErr.status
+			}
+			recordOperation(ctx, r.audit, status, err)
+		}()
+	}
+
+	if r.contentType == "" {
+		r.contentType = CONTENT_TYPE_JSON
+	}
+	if r.accept == "" {
+		r.accept = CONTENT_TYPE_JSON
+	}
+	if r.contentType.MIME() == "" {
+		return nil, fmt.Errorf("unsupported content type %q", r.contentType)
+	}
+	if r.accept.MIME() == "" {
+		return nil, fmt.Errorf("unsupported accept type %q", r.accept)
+	}
+
+	mutating := r.audit != nil
+	if mutating && config.Global.Connection.StrictVersion {
+		if err := preflightCLIVersion(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var payload []byte
+	switch body := r.body.(type) {
+	case nil:
+	case []byte:
+		payload = body
+	default:
+		payload, err = r.contentType.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling payload: %v", err)
+		}
+	}
+
+	uncompressed := payload
+	compressed := false
+	if len(payload) > GZIP_MIN_SIZE {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(payload); err != nil {
+			return nil, fmt.Errorf("error compressing payload: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("error compressing payload: %v", err)
+		}
+		payload = buf.Bytes()
+		compressed = true
+	}
+
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, config.Global.Connection.URL+r.path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+config.Global.Connection.AuthToken)
+	req.Header.Set("Accept", r.accept.MIME())
+	req.Header.Set("User-Agent", UserAgent())
+	if payload != nil {
+		req.Header.Set("Content-Type", r.contentType.MIME())
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, values := range r.header {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	if r.cache && config.Global.Cache.Enabled {
+		resp, err = cachedDo(req, uncompressed)
+	} else {
+		resp, err = httpClient().Do(req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %v", err)
+	}
+
+	checkAPIVersion(resp)
+	if err := checkCLIVersion(resp, mutating); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && !r.keepErrors {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &statusError{status: resp.StatusCode, body: string(body)}
+	}
+
+	return resp, nil
+}
+
+// statusError is returned for non-2xx responses
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.status, e.body)
+}
+
+// decodeResponse decodes the response body into v according to the
+// response's Content-Type, defaulting to JSON, and closes the body. JSON
+// fields unknown to v are ignored, unless strict decoding is enabled.
+func decodeResponse(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	contentType, err := ParseContentType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		contentType = CONTENT_TYPE_JSON
+	}
+	if _, isProto := v.(proto.Message); contentType == CONTENT_TYPE_JSON && !isProto {
+		err = unmarshalJSON(data, v)
+	} else {
+		err = contentType.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	return nil
+}
+
+// readResponse returns the response body as a string and closes it
+func readResponse(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %v", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,238 @@
+package client
+
+// Workload revisions are kept by the server where it supports them. Otherwise
+// each workload created through the CLI is stored locally, so it can still be
+// rolled back from the machine it was submitted from.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/revision"
+	"github.com/cedana/cedana-cli/pkg/workload"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ROLLBACK_DELETE_TIMEOUT is how long a local rollback waits for the
+	// current workload to be deleted before creating the previous revision
+	ROLLBACK_DELETE_TIMEOUT = 2 * time.Minute
+
+	rollbackPollInterval = 2 * time.Second
+)
+
+// GetWorkloadRevisions makes a POST request to fetch the revisions of a
+// workload, oldest first. If the server does not keep revisions, those stored
+// locally are returned instead, and local is set.
+func GetWorkloadRevisions(ctx context.Context, clusterName, namespace, name string) (revisions []revision.Revision, local bool, err error) {
+	resp, err := clientRequest(ctx, request{
+		method:     "POST",
+		path:       "/cluster/workload/revisions",
+		body:       workloadRef(clusterName, namespace, name),
+		keepErrors: true,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("error fetching revisions: %v", err)
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		body, _ := readResponse(resp)
+		if !endpointMissing(resp.StatusCode, body) {
+			return nil, false, fmt.Errorf("error fetching revisions: %v", &statusError{status: resp.StatusCode, body: body})
+		}
+		log.Debug().Int("status", resp.StatusCode).Msg("server does not keep revisions, using local store")
+		revisions, err := revisionStore().List(revision.Key{Cluster: clusterName, Namespace: namespace, Name: name})
+		if err != nil {
+			return nil, true, fmt.Errorf("error reading local revisions: %v", err)
+		}
+		return revisions, true, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := readResponse(resp)
+		return nil, false, fmt.Errorf("error fetching revisions: %v", &statusError{status: resp.StatusCode, body: body})
+	}
+	if err := decodeResponse(resp, &revisions); err != nil {
+		return nil, false, err
+	}
+	return revisions, false, nil
+}
+
+// RollbackWorkload makes a POST request to roll a workload back to a previous
+// revision. If local is set, the server does not keep revisions, so the
+// workload is instead deleted and created again from the locally stored
+// revision, which is recorded as a new revision. The workload is only created
+// again once the deletion has completed.
+func RollbackWorkload(ctx context.Context, clusterName, namespace, name string, to int, local bool) (string, error) {
+	if !local {
+		payload := workloadRef(clusterName, namespace, name)
+		payload["revision"] = to
+		return workloadAction(ctx, "POST", "/cluster/workload/rollback", payload, &operation{
+			action:   fmt.Sprintf("rollback workload to revision %d", to),
+			resource: workloadResource(clusterName, namespace, name),
+		})
+	}
+
+	revisions, err := revisionStore().List(revision.Key{Cluster: clusterName, Namespace: namespace, Name: name})
+	if err != nil {
+		return "", fmt.Errorf("error reading local revisions: %v", err)
+	}
+	target, ok := revision.Find(revisions, to)
+	if !ok {
+		return "", fmt.Errorf("revision %d of workload %s not found", to, name)
+	}
+
+	manifest, err := decodeManifest(target.Payload, ContentType(target.ContentType))
+	if err != nil {
+		return "", fmt.Errorf("error decoding revision %d: %v", to, err)
+	}
+	if _, err := DeleteWorkloadByName(ctx, clusterName, namespace, manifest.Workload.Kind, name); err != nil {
+		// the workload may already be gone, which waitDeleted finds out
+		fmt.Fprintf(WarningOutput, "Warning: failed to delete workload %s before rollback: %v\n", name, err)
+	}
+	if err := waitDeleted(ctx, clusterName, namespace, manifest.Workload.Kind, name); err != nil {
+		return "", err
+	}
+	return CreateWorkload(ctx, target.Payload, target.ContentType)
+}
+
+// waitDeleted polls the workloads of a namespace until the given one is gone.
+// Kubernetes deletes jobs asynchronously, after their pods and finalizers, so
+// a workload of the same name can't be created until then.
+func waitDeleted(ctx context.Context, clusterName, namespace, kind, name string) error {
+	deadline := time.After(ROLLBACK_DELETE_TIMEOUT)
+	ticker := time.NewTicker(rollbackPollInterval)
+	defer ticker.Stop()
+
+	for {
+		workloads, err := GetClusterWorkloads(ctx, clusterName, namespace)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, w := range workloads {
+			if w.Name == name && (kind == "" || w.Kind == "" || w.Kind == kind) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("workload %s still exists after %s, not rolling back", name, ROLLBACK_DELETE_TIMEOUT)
+		case <-ticker.C:
+		}
+	}
+}
+
+// endpointMissing reports whether a 404 or 501 response means the server does
+// not serve an endpoint at all, rather than that the resource asked for does
+// not exist. Unknown routes are answered by the router with a plain text body,
+// while the endpoints themselves answer with a JSON error.
+func endpointMissing(status int, body string) bool {
+	if status == http.StatusNotImplemented {
+		return true
+	}
+	body = strings.TrimSpace(body)
+	return !strings.HasPrefix(body, "{") || !json.Valid([]byte(body))
+}
+
+// saveRevision stores a created workload in the local store of revisions.
+// Failing to store it never fails the creation, but is warned about.
+func saveRevision(payload []byte, ct ContentType) {
+	manifest, err := decodeManifest(payload, ct)
+	if err != nil {
+		log.Debug().Err(err).Msg("not storing revision of undecodable workload payload")
+		return
+	}
+	key := revision.Key{
+		Cluster:   manifest.ClusterName,
+		Namespace: manifest.Workload.Metadata.Namespace,
+		Name:      manifest.Workload.Metadata.Name,
+	}
+	if _, err := revisionStore().Add(key, payload, string(ct)); err != nil {
+		log.Warn().Err(err).Str("workload", key.String()).Msg("failed to store workload revision")
+		fmt.Fprintf(WarningOutput, "Warning: failed to store revision of workload %s: %v\n", key, err)
+	}
+}
+
+func revisionStore() *revision.Store {
+	return revision.NewStore(config.Dir)
+}
+
+func decodeManifest(payload []byte, ct ContentType) (*workload.Manifest, error) {
+	var manifest workload.Manifest
+	if err := ct.Unmarshal(payload, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.ClusterName == "" || manifest.Workload.Metadata.Name == "" {
+		return nil, fmt.Errorf("payload has no cluster or workload name")
+	}
+	return &manifest, nil
+}
+
+func workloadRef(clusterName, namespace, name string) map[string]any {
+	return map[string]any{
+		"cluster_name": clusterName,
+		"namespace":    namespace,
+		"name":         name,
+	}
+}
+
+// GetWorkloadSpec makes a POST request to fetch the stored spec of a
+// workload, including its status. If the server does not serve specs, the
+// latest revision of the workload is used instead.
+func GetWorkloadSpec(ctx context.Context, clusterName, namespace, name string) (map[string]any, error) {
+	resp, err := clientRequest(ctx, request{
+		method:     "POST",
+		path:       "/cluster/workload/spec",
+		body:       workloadRef(clusterName, namespace, name),
+		keepErrors: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching workload spec: %v", err)
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		body, _ := readResponse(resp)
+		if !endpointMissing(resp.StatusCode, body) {
+			return nil, fmt.Errorf("error fetching workload spec: %v", &statusError{status: resp.StatusCode, body: body})
+		}
+		log.Debug().Int("status", resp.StatusCode).Msg("server does not serve workload specs, using latest revision")
+		return latestRevisionSpec(ctx, clusterName, namespace, name)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := readResponse(resp)
+		return nil, fmt.Errorf("error fetching workload spec: %v", &statusError{status: resp.StatusCode, body: body})
+	}
+	var spec map[string]any
+	if err := decodeResponse(resp, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func latestRevisionSpec(ctx context.Context, clusterName, namespace, name string) (map[string]any, error) {
+	revisions, _, err := GetWorkloadRevisions(ctx, clusterName, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("no stored spec of workload %s, the server does not keep them and it was not created from this machine", name)
+	}
+	latest := revisions[len(revisions)-1]
+	var payload struct {
+		Workload map[string]any `json:"workload" yaml:"workload"`
+	}
+	if err := ContentType(latest.ContentType).Unmarshal(latest.Payload, &payload); err != nil || payload.Workload == nil {
+		return nil, fmt.Errorf("error decoding revision %d of workload %s: %v", latest.Revision, name, err)
+	}
+	return payload.Workload, nil
+}
@@ -0,0 +1,403 @@
+package client
+
+// HTTP tracing for debugging API calls. Every exchange is logged at debug
+// level and/or recorded to a HAR file, with secrets redacted from both.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	REDACTED = "[REDACTED]"
+
+	HAR_FILE_PERM = 0o600 // traces may hold secrets that are not redacted, keep them private
+)
+
+// Headers whose values are always redacted
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+type tracingTransport struct {
+	base         http.RoundTripper
+	log          bool
+	har          *harRecorder
+	redactFields map[string]bool
+}
+
+func newTracingTransport(base http.RoundTripper, trace config.Trace) *tracingTransport {
+	t := &tracingTransport{
+		base:         base,
+		log:          trace.HTTP,
+		redactFields: make(map[string]bool),
+	}
+	if trace.HARFile != "" {
+		t.har = getHARRecorder(trace.HARFile)
+	}
+	for _, field := range trace.RedactFields {
+		t.redactFields[strings.ToLower(field)] = true
+	}
+	return t
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	rawReqBody := reqBody
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		reqBody = gunzip(reqBody)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		if t.log {
+			log.Debug().
+				Str("method", req.Method).
+				Str("url", req.URL.String()).
+				Dur("latency", latency).
+				Interface("request_headers", t.redactHeaders(req.Header)).
+				Str("request_body", t.redactBody(req.Header.Get("Content-Type"), reqBody)).
+				Err(err).
+				Msg("http request failed")
+		}
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if t.log {
+		log.Debug().
+			Str("method", req.Method).
+			Str("url", req.URL.String()).
+			Int("status", resp.StatusCode).
+			Dur("latency", latency).
+			Interface("request_headers", t.redactHeaders(req.Header)).
+			Str("request_body", t.redactBody(req.Header.Get("Content-Type"), reqBody)).
+			Interface("response_headers", t.redactHeaders(resp.Header)).
+			Str("response_body", t.redactBody(resp.Header.Get("Content-Type"), respBody)).
+			Msg("http request")
+	}
+
+	if t.har != nil {
+		t.har.record(harEntry{
+			StartedDateTime: start.Format(time.RFC3339Nano),
+			Time:            float64(latency.Microseconds()) / 1000,
+			Request: harRequest{
+				Method:      req.Method,
+				URL:         req.URL.String(),
+				HTTPVersion: req.Proto,
+				Headers:     harHeaders(t.redactHeaders(req.Header)),
+				QueryString: harQuery(req),
+				PostData:    harPostData(req.Header.Get("Content-Type"), t.redactBody(req.Header.Get("Content-Type"), reqBody)),
+				HeadersSize: -1,
+				BodySize:    len(rawReqBody),
+			},
+			Response: harResponse{
+				Status:      resp.StatusCode,
+				StatusText:  http.StatusText(resp.StatusCode),
+				HTTPVersion: resp.Proto,
+				Headers:     harHeaders(t.redactHeaders(resp.Header)),
+				Content: harContent{
+					Size:     len(respBody),
+					MimeType: resp.Header.Get("Content-Type"),
+					Text:     t.redactBody(resp.Header.Get("Content-Type"), respBody),
+				},
+				HeadersSize: -1,
+				BodySize:    len(respBody),
+			},
+			Cache: struct{}{},
+			Timings: harTimings{
+				Send:    0,
+				Wait:    float64(latency.Microseconds()) / 1000,
+				Receive: 0,
+			},
+		})
+	}
+
+	return resp, nil
+}
+
+// gunzip decompresses a gzipped body for display, returning it as is on failure
+func gunzip(body []byte) []byte {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decompressed
+}
+
+func (t *tracingTransport) redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		values := redacted.Values(name)
+		for i, v := range values {
+			if scheme, _, ok := strings.Cut(v, " "); ok && name == "Authorization" {
+				values[i] = scheme + " " + REDACTED
+			} else {
+				values[i] = REDACTED
+			}
+		}
+	}
+	return redacted
+}
+
+// redactBody replaces the values of sensitive fields in a JSON or YAML body.
+// Other bodies, such as protobuf, are returned as is.
+func (t *tracingTransport) redactBody(contentType string, body []byte) string {
+	if len(body) == 0 || len(t.redactFields) == 0 {
+		return string(body)
+	}
+	if ct, err := ParseContentType(contentType); err == nil && ct == CONTENT_TYPE_YAML {
+		return t.redactYAML(body)
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(t.redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func (t *tracingTransport) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if t.redactFields[strings.ToLower(key)] {
+				v[key] = REDACTED
+			} else {
+				v[key] = t.redactValue(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = t.redactValue(value)
+		}
+	}
+	return v
+}
+
+// redactYAML replaces the values of sensitive fields in every document of a
+// YAML body, keeping the order of keys
+func (t *tracingTransport) redactYAML(body []byte) string {
+	var redacted bytes.Buffer
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	encoder := yaml.NewEncoder(&redacted)
+	encoder.SetIndent(2)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err == io.EOF {
+			break
+		} else if err != nil {
+			return string(body)
+		}
+		t.redactNode(&document)
+		if err := encoder.Encode(&document); err != nil {
+			return string(body)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return string(body)
+	}
+	return redacted.String()
+}
+
+func (t *tracingTransport) redactNode(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			t.redactNode(child)
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if t.redactFields[strings.ToLower(node.Content[i].Value)] {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: REDACTED}
+		} else {
+			t.redactNode(node.Content[i+1])
+		}
+	}
+}
+
+////////////////////
+/////// HAR ////////
+////////////////////
+
+// Types below follow the HAR 1.2 spec: http://www.softwareishard.com/blog/har-12-spec/
+
+type (
+	har struct {
+		Log harLog `json:"log"`
+	}
+
+	harLog struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	}
+
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+	}
+
+	harRequest struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		PostData    *harPostBody   `json:"postData,omitempty"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      int            `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Headers     []harNameValue `json:"headers"`
+		Content     harContent     `json:"content"`
+		RedirectURL string         `json:"redirectURL"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int            `json:"bodySize"`
+	}
+
+	harNameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harPostBody struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	harContent struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// harRecorder accumulates exchanges and rewrites the HAR file after each one,
+// so the file is complete even if the command exits abruptly
+type harRecorder struct {
+	mu   sync.Mutex
+	path string
+	har  har
+}
+
+var (
+	harRecordersMu sync.Mutex
+	harRecorders   = make(map[string]*harRecorder)
+)
+
+func getHARRecorder(path string) *harRecorder {
+	harRecordersMu.Lock()
+	defer harRecordersMu.Unlock()
+
+	if r, ok := harRecorders[path]; ok {
+		return r
+	}
+	r := &harRecorder{
+		path: path,
+		har: har{Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "cedana-cli", Version: cliVersion},
+			Entries: []harEntry{},
+		}},
+	}
+	harRecorders[path] = r
+	return r
+}
+
+func (r *harRecorder) record(entry harEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.har.Log.Entries = append(r.har.Log.Entries, entry)
+
+	data, err := json.MarshalIndent(r.har, "", "  ")
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to marshal HAR")
+		return
+	}
+	if err := os.WriteFile(r.path, data, HAR_FILE_PERM); err != nil {
+		log.Warn().Err(err).Str("path", r.path).Msg("failed to write HAR file")
+	}
+}
+
+func harHeaders(headers http.Header) []harNameValue {
+	list := []harNameValue{}
+	for name, values := range headers {
+		for _, v := range values {
+			list = append(list, harNameValue{Name: name, Value: v})
+		}
+	}
+	return list
+}
+
+func harQuery(req *http.Request) []harNameValue {
+	list := []harNameValue{}
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			list = append(list, harNameValue{Name: name, Value: v})
+		}
+	}
+	return list
+}
+
+func harPostData(mimeType string, text string) *harPostBody {
+	if text == "" {
+		return nil
+	}
+	return &harPostBody{MimeType: mimeType, Text: text}
+}
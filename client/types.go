@@ -1,31 +1,190 @@
 package client
 
+import "time"
+
 // Node represents a node in the cluster response
 type Node struct {
-	ID           string `json:"ID"`
-	ClusterID    string `json:"ClusterID"`
-	Name         string `json:"Name"`
-	Status       string `json:"Status"`
-	ComputeType  string `json:"ComputeType"`
-	InstanceType string `json:"InstanceType"`
-	Region       string `json:"Region"`
+	ID           string            `json:"ID"`
+	ClusterID    string            `json:"ClusterID"`
+	Name         string            `json:"Name"`
+	Status       string            `json:"Status"`
+	ComputeType  string            `json:"ComputeType"`
+	InstanceType string            `json:"InstanceType"`
+	Region       string            `json:"Region"`
+	Labels       map[string]string `json:"Labels"`
+	CreatedAt    time.Time         `json:"CreatedAt"`
+	UpdatedAt    time.Time         `json:"UpdatedAt"`
 }
 
 // Cluster represents a cluster in the response
 type Cluster struct {
-	ID       string      `json:"ID"`
-	OrgID    string      `json:"OrgID"`
-	Name     string      `json:"Name"`
-	Status   string      `json:"Status"`
-	Metadata interface{} `json:"Metadata"`
+	ID        string            `json:"ID"`
+	OrgID     string            `json:"OrgID"`
+	Name      string            `json:"Name"`
+	Status    string            `json:"Status"`
+	Metadata  ClusterMetadata   `json:"Metadata"`
+	Labels    map[string]string `json:"Labels"`
+	CreatedAt time.Time         `json:"CreatedAt"`
+	UpdatedAt time.Time         `json:"UpdatedAt"`
+}
+
+// ClusterMetadata is what the platform knows about the underlying Kubernetes cluster
+type ClusterMetadata struct {
+	Provider          string `json:"Provider"`
+	Region            string `json:"Region"`
+	KubernetesVersion string `json:"KubernetesVersion"`
+	Endpoint          string `json:"Endpoint"`
+	// HelperVersion is the version of the Cedana helper running in the cluster
+	HelperVersion string `json:"HelperVersion"`
 }
 
 type Pod struct {
-	ID        string      `json:"ID"`
-	ClusterID string      `json:"ClusterID"`
-	NodeID    string      `json:"NodeID"`
-	Name      string      `json:"Name"`
-	Namespace string      `json:"Namespace"`
-	Status    string      `json:"Status"`
-	Metadata  interface{} `json:"Metadata"`
+	ID        string            `json:"ID"`
+	ClusterID string            `json:"ClusterID"`
+	NodeID    string            `json:"NodeID"`
+	Name      string            `json:"Name"`
+	Namespace string            `json:"Namespace"`
+	Status    string            `json:"Status"`
+	Metadata  PodMetadata       `json:"Metadata"`
+	Labels    map[string]string `json:"Labels"`
+	CreatedAt time.Time         `json:"CreatedAt"`
+	UpdatedAt time.Time         `json:"UpdatedAt"`
+}
+
+// PodMetadata is what the platform knows about a pod beyond its status
+type PodMetadata struct {
+	Images       []string          `json:"Images"`
+	Annotations  map[string]string `json:"Annotations"`
+	QOSClass     string            `json:"QOSClass"`
+	RestartCount int               `json:"RestartCount"`
+	StartedAt    *time.Time        `json:"StartedAt"`
+	// Owner is the workload owning the pod, e.g. "Job/train-llm"
+	Owner string `json:"Owner"`
+}
+
+// Workload represents a workload submitted to a cluster, e.g. a Job
+type Workload struct {
+	Name      string `json:"Name"`
+	Namespace string `json:"Namespace"`
+	Kind      string `json:"Kind"`
+	Status    string `json:"Status"`
+	// Queue is the kueue local queue the workload was submitted to
+	Queue         string            `json:"Queue"`
+	PriorityClass string            `json:"PriorityClass"`
+	Labels        map[string]string `json:"Labels"`
+	CreatedAt     time.Time         `json:"CreatedAt"`
+	UpdatedAt     time.Time         `json:"UpdatedAt"`
+}
+
+// Event represents a kubernetes event recorded for a node, pod or workload
+type Event struct {
+	// Type is either "Normal" or "Warning"
+	Type     string    `json:"Type"`
+	Reason   string    `json:"Reason"`
+	Message  string    `json:"Message"`
+	Count    int       `json:"Count"`
+	LastSeen time.Time `json:"LastSeen"`
+}
+
+// ResourceUsage represents the observed utilisation of a node, pod or workload
+// alongside its requested and limited resources. CPU values are in cores,
+// memory values in bytes and GPU values in number of devices. GPU fields are
+// nil when the platform does not report GPU metrics for the resource.
+type ResourceUsage struct {
+	Name      string `json:"Name"`
+	Namespace string `json:"Namespace"`
+
+	CPUUsage   float64 `json:"CPUUsage"`
+	CPURequest float64 `json:"CPURequest"`
+	CPULimit   float64 `json:"CPULimit"`
+
+	MemoryUsage   int64 `json:"MemoryUsage"`
+	MemoryRequest int64 `json:"MemoryRequest"`
+	MemoryLimit   int64 `json:"MemoryLimit"`
+
+	GPUUsage   *float64 `json:"GPUUsage"`
+	GPURequest *float64 `json:"GPURequest"`
+	GPULimit   *float64 `json:"GPULimit"`
+}
+
+// CostRecord represents the cost incurred by a single workload on a single
+// node over the queried window. Costs are in USD.
+type CostRecord struct {
+	ClusterName  string            `json:"ClusterName"`
+	Namespace    string            `json:"Namespace"`
+	Workload     string            `json:"Workload"`
+	NodeID       string            `json:"NodeID"`
+	InstanceType string            `json:"InstanceType"`
+	Region       string            `json:"Region"`
+	Labels       map[string]string `json:"Labels"`
+	// PricingModel is either "on-demand" or "spot"
+	PricingModel string  `json:"PricingModel"`
+	Hours        float64 `json:"Hours"`
+	Cost         float64 `json:"Cost"`
+	// Savings is the cost avoided by migrating the workload instead of
+	// restarting it or keeping it on on-demand capacity
+	Savings float64 `json:"Savings"`
+}
+
+// NodePool represents a karpenter nodepool of a cluster and the instance
+// types it can provision
+type NodePool struct {
+	Name string `json:"Name"`
+	// CapacityTypes lists the purchase options allowed, "on-demand" and/or "spot"
+	CapacityTypes []string       `json:"CapacityTypes"`
+	InstanceTypes []InstanceType `json:"InstanceTypes"`
+}
+
+// InstanceType represents an instance type with its allocatable resources
+// and hourly prices in USD
+type InstanceType struct {
+	Name          string  `json:"Name"`
+	Region        string  `json:"Region"`
+	CPU           float64 `json:"CPU"`
+	Memory        int64   `json:"Memory"`
+	GPU           float64 `json:"GPU"`
+	OnDemandPrice float64 `json:"OnDemandPrice"`
+	SpotPrice     float64 `json:"SpotPrice"`
+}
+
+// ClusterQueue represents a kueue cluster queue, its quota and current usage.
+// CPU is in cores, memory in bytes and GPU in number of devices.
+type ClusterQueue struct {
+	Name        string   `json:"Name"`
+	LocalQueues []string `json:"LocalQueues"`
+
+	CPUQuota    float64 `json:"CPUQuota"`
+	MemoryQuota int64   `json:"MemoryQuota"`
+	GPUQuota    float64 `json:"GPUQuota"`
+
+	CPUUsed    float64 `json:"CPUUsed"`
+	MemoryUsed int64   `json:"MemoryUsed"`
+	GPUUsed    float64 `json:"GPUUsed"`
+
+	PendingWorkloads  int `json:"PendingWorkloads"`
+	AdmittedWorkloads int `json:"AdmittedWorkloads"`
+}
+
+// Interruption represents a simulated spot-termination notice sent to one or
+// more nodes, and the migration progress of the pods that were running on them
+type Interruption struct {
+	ID          string         `json:"ID"`
+	ClusterName string         `json:"ClusterName"`
+	Nodes       []string       `json:"Nodes"`
+	NoticeAt    time.Time      `json:"NoticeAt"`
+	Pods        []PodMigration `json:"Pods"`
+}
+
+// PodMigration represents the checkpoint/restore progress of a single pod
+// affected by an interruption
+type PodMigration struct {
+	Name      string `json:"Name"`
+	Namespace string `json:"Namespace"`
+	Node      string `json:"Node"`
+	// Status is one of "pending", "checkpointing", "checkpointed", "restoring", "restored" or "failed"
+	Status         string     `json:"Status"`
+	CheckpointedAt *time.Time `json:"CheckpointedAt"`
+	RestoredAt     *time.Time `json:"RestoredAt"`
+	RestoredNode   string     `json:"RestoredNode"`
+	Error          string     `json:"Error"`
 }
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetNodeUsage makes a POST request to fetch resource usage for all nodes of a given cluster
+func GetNodeUsage(ctx context.Context, clusterName string) ([]ResourceUsage, error) {
+	return getUsage(ctx, "/cluster/nodes/usage", clusterName)
+}
+
+// GetPodUsage makes a POST request to fetch resource usage for all pods under given namespace of a cluster
+func GetPodUsage(ctx context.Context, clusterName string, clusterNamespace string) ([]ResourceUsage, error) {
+	return getUsage(ctx, "/cluster/pods/usage/"+clusterNamespace, clusterName)
+}
+
+// GetWorkloadUsage makes a POST request to fetch resource usage for all workloads under given namespace of a cluster
+func GetWorkloadUsage(ctx context.Context, clusterName string, clusterNamespace string) ([]ResourceUsage, error) {
+	return getUsage(ctx, "/cluster/workloads/usage/"+clusterNamespace, clusterName)
+}
+
+func getUsage(ctx context.Context, path string, clusterName string) ([]ResourceUsage, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   path,
+		body:   map[string]string{"cluster_name": clusterName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching usage: %v", err)
+	}
+	var usage []ResourceUsage
+	if err := decodeResponse(resp, &usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
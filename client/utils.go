@@ -1,60 +1,20 @@
 package client
 
 import (
-	"bytes"
-	"fmt"
-	"io"
 	"net/http"
-)
-
-// helper function for all requests
-func clientRequest(reqType string, apiURL string, apiToken string, payload []byte) (*http.Response, error) {
-	var err error
-
-	req, err := http.NewRequest(reqType, apiURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
-	}
 
-	return resp, nil
-}
-
-// TODO: minimize duplicated code
-func yamlClientRequest(reqType string, apiURL string, apiToken string, payload []byte) (*http.Response, error) {
-	var err error
-
-	req, err := http.NewRequest(reqType, apiURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiToken)
-	req.Header.Set("Content-Type", "application/yaml")
+	"github.com/cedana/cedana-cli/pkg/config"
+)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
+// httpClient returns the client used for all requests, tracing exchanges if enabled.
+// The timeout covers the whole exchange, including reading the response body.
+func httpClient() *http.Client {
+	transport := http.DefaultTransport
+	if trace := config.Global.Trace; trace.HTTP || trace.HARFile != "" {
+		transport = newTracingTransport(transport, trace)
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Global.Connection.Timeout,
 	}
-
-	return resp, nil
 }
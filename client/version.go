@@ -0,0 +1,197 @@
+package client
+
+// Version negotiation with the server. Every request carries the CLI version
+// in its User-Agent, and the server may answer with the oldest CLI version it
+// still supports.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+const MIN_CLI_VERSION_HEADER = "X-Cedana-Min-CLI-Version"
+
+// gitDescribeSuffix matches the suffix of versions built from commits after a
+// tag, e.g. "v0.4.2-3-g1a2b3c4-dirty"
+var gitDescribeSuffix = regexp.MustCompile(`-\d+-g[0-9a-f]+(-dirty)?$`)
+
+var (
+	cliVersion = "dev"
+	cliCommit  = "none"
+
+	cliVersionWarning sync.Once
+
+	preflightOnce sync.Once
+	preflightErr  error
+)
+
+// SetVersion sets the CLI version and commit sent with every request
+func SetVersion(version, commit string) {
+	cliVersion = version
+	cliCommit = commit
+}
+
+// UserAgent returns the User-Agent sent with every request, e.g.
+// "cedana-cli/v0.4.2 (3f2c1ab; linux/amd64)"
+func UserAgent() string {
+	return fmt.Sprintf("cedana-cli/%s (%s; %s/%s)", cliVersion, cliCommit, runtime.GOOS, runtime.GOARCH)
+}
+
+// ServerVersion is the version of the server, and the API and CLI versions it supports
+type ServerVersion struct {
+	Version string `json:"Version"`
+	// APIVersions are the API schema versions the server can speak
+	APIVersions []string `json:"APIVersions"`
+	// MinCLIVersion is the oldest CLI version the server supports, if any
+	MinCLIVersion string `json:"MinCLIVersion"`
+}
+
+// GetServerVersion makes a GET request to fetch the version of the server
+func GetServerVersion(ctx context.Context) (*ServerVersion, error) {
+	resp, err := clientRequest(ctx, request{method: "GET", path: "/version"})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching server version: %v", err)
+	}
+	var version ServerVersion
+	if err := decodeResponse(resp, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// UnsupportedVersionError is returned for requests the server answered with a
+// minimum CLI version above this one, when strict version checking is enabled
+type UnsupportedVersionError struct {
+	Version    string
+	MinVersion string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("cedana-cli %s is no longer supported by the server, which requires %s or newer. Please upgrade cedana-cli", e.Version, e.MinVersion)
+}
+
+// CLISupported reports whether this CLI is at least minVersion. A pre-release
+// is older than its release, so v0.5.0-rc1 does not meet a minimum of v0.5.0.
+// Development builds, whose version can't be compared, are always supported.
+func CLISupported(minVersion string) bool {
+	if minVersion == "" {
+		return true
+	}
+	cmp, err := CompareVersions(cliVersion, minVersion)
+	if err != nil {
+		log.Debug().Err(err).Str("cli", cliVersion).Str("min", minVersion).Msg("cannot compare CLI versions")
+		return true
+	}
+	return cmp >= 0
+}
+
+// checkCLIVersion warns, once per invocation, if the server reports this CLI
+// is older than the minimum version it supports. In strict mode, an error is
+// returned instead for reads. Mutating requests have already been carried out
+// by the time their response arrives, so they are checked beforehand by
+// preflightCLIVersion, and only warned about here.
+func checkCLIVersion(resp *http.Response, mutating bool) error {
+	minVersion := resp.Header.Get(MIN_CLI_VERSION_HEADER)
+	if CLISupported(minVersion) {
+		return nil
+	}
+	err := &UnsupportedVersionError{Version: cliVersion, MinVersion: minVersion}
+	if config.Global.Connection.StrictVersion && !mutating {
+		return err
+	}
+	cliVersionWarning.Do(func() {
+		log.Warn().Str("cli", cliVersion).Str("min", minVersion).Msg("CLI is older than server supports")
+		fmt.Fprintf(WarningOutput, "Warning: %s.\n", err)
+	})
+	return nil
+}
+
+// preflightCLIVersion fetches the server version, once per invocation, to
+// check that the server supports this CLI before the first mutating request is
+// sent. If the server version can't be fetched, e.g. from an older server, the
+// check is left to the response of the request itself.
+func preflightCLIVersion(ctx context.Context) error {
+	preflightOnce.Do(func() {
+		resp, err := clientRequest(ctx, request{method: "GET", path: "/version", keepErrors: true})
+		if err != nil {
+			var unsupported *UnsupportedVersionError
+			if errors.As(err, &unsupported) {
+				preflightErr = err
+				return
+			}
+			log.Debug().Err(err).Msg("cannot fetch server version, minimum CLI version unknown")
+			return
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
+			log.Debug().Int("status", resp.StatusCode).Msg("cannot fetch server version, minimum CLI version unknown")
+			return
+		}
+		var version ServerVersion
+		if err := decodeResponse(resp, &version); err != nil {
+			log.Debug().Err(err).Msg("cannot decode server version, minimum CLI version unknown")
+			return
+		}
+		if !CLISupported(version.MinCLIVersion) {
+			preflightErr = &UnsupportedVersionError{Version: cliVersion, MinVersion: version.MinCLIVersion}
+		}
+	})
+	return preflightErr
+}
+
+// CompareVersions returns 1 if version a is newer than b, -1 if it is older
+// and 0 if they are the same. A pre-release is older than its release, e.g.
+// v0.5.0-rc1 is older than v0.5.0. Versions whose order is unknown, such as
+// development builds, builds from untagged commits or two pre-releases of the
+// same release, return an error.
+func CompareVersions(a, b string) (int, error) {
+	// build metadata does not affect precedence
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+	if a == b {
+		return 0, nil
+	}
+	for _, version := range []string{a, b} {
+		if gitDescribeSuffix.MatchString(version) {
+			return 0, fmt.Errorf("cannot compare %s, built from an untagged commit", version)
+		}
+	}
+
+	releaseA, releaseB := releaseVersion(a), releaseVersion(b)
+	newer, err := NewerVersion(releaseA, releaseB)
+	if err != nil {
+		return 0, err
+	}
+	if newer {
+		return 1, nil
+	}
+	if older, _ := NewerVersion(releaseB, releaseA); older {
+		return -1, nil
+	}
+
+	preA, preB := releaseA != a, releaseB != b
+	switch {
+	case preB && !preA:
+		return 1, nil
+	case preA && !preB:
+		return -1, nil
+	default:
+		return 0, fmt.Errorf("cannot compare pre-releases %s and %s", a, b)
+	}
+}
+
+// releaseVersion strips pre-release and build suffixes, e.g. "v1.2.0-rc1" becomes "v1.2.0"
+func releaseVersion(version string) string {
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	return version
+}
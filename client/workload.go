@@ -1,59 +1,147 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
-	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/workload"
 )
 
-// GetClusterNodes makes a POST request to fetch nodes for a given cluster
-func CreateWorkload(payload []byte, contentType string) (string, error) {
-	cedanaURL := config.Global.Connection.URL
-	cedanaAuthToken := config.Global.Connection.AuthToken
-
-	var resp *http.Response
-	var err error
-
-	if contentType == "yaml" {
-		resp, err = yamlClientRequest("POST", cedanaURL+"/cluster/workload", cedanaAuthToken, payload)
-	} else {
-		resp, err = clientRequest("POST", cedanaURL+"/cluster/workload", cedanaAuthToken, payload)
+// GetClusterWorkloads makes a POST request to fetch the workloads under given namespace of a cluster
+func GetClusterWorkloads(ctx context.Context, clusterName string, clusterNamespace string) ([]Workload, error) {
+	resp, err := clientRequest(ctx, request{
+		method: "POST",
+		path:   "/cluster/workloads/" + clusterNamespace,
+		body:   map[string]string{"cluster_name": clusterName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching workloads: %v", err)
 	}
+	var workloads []Workload
+	if err := decodeResponse(resp, &workloads); err != nil {
+		return nil, err
+	}
+	return workloads, nil
+}
 
+// CreateWorkload makes a POST request to create a workload from a payload
+// encoded as the given content type (json, yaml or protobuf).
+// The payload is stored as a new revision of the workload.
+func CreateWorkload(ctx context.Context, payload []byte, contentType string) (string, error) {
+	resp, err := workloadPayloadAction(ctx, "POST", "create workload", payload, contentType)
 	if err != nil {
-		return "", fmt.Errorf("%v", err)
+		return "", err
+	}
+	ct, _ := ParseContentType(contentType) // already validated
+	saveRevision(payload, ct)
+	return resp, nil
+}
+
+// DeleteWorkload makes a DELETE request to delete the workload described by a
+// payload encoded as the given content type (json, yaml or protobuf)
+func DeleteWorkload(ctx context.Context, payload []byte, contentType string) (string, error) {
+	return workloadPayloadAction(ctx, "DELETE", "delete workload", payload, contentType)
+}
+
+// DeleteWorkloadByName deletes a workload given by name, rather than by its
+// full payload. An empty kind defaults to a Job.
+func DeleteWorkloadByName(ctx context.Context, clusterName, namespace, kind, name string) (string, error) {
+	if kind == "" {
+		kind = "Job"
 	}
-	defer resp.Body.Close()
-	bodyBytes, err := io.ReadAll(resp.Body)
+	payload, err := json.Marshal(workload.Manifest{
+		ClusterName: clusterName,
+		Workload: workload.Job{
+			APIVersion: "batch/v1",
+			Kind:       kind,
+			Metadata:   workload.ObjectMeta{Name: name, Namespace: namespace},
+		},
+	})
 	if err != nil {
-		return "", fmt.Errorf("%v", err)
+		return "", fmt.Errorf("error marshaling payload: %v", err)
 	}
-	return string(bodyBytes), nil
+	return DeleteWorkload(ctx, payload, string(CONTENT_TYPE_JSON))
 }
 
-func DeleteWorkload(payload []byte, contentType string) (string, error) {
-	cedanaURL := config.Global.Connection.URL
-	cedanaAuthToken := config.Global.Connection.AuthToken
+// SuspendWorkload makes a POST request to suspend a queued or running workload.
+// If checkpoint is set, the workload's pods are checkpointed before being
+// stopped so that a later resume continues from the saved state.
+func SuspendWorkload(ctx context.Context, clusterName, namespace, name string, checkpoint bool) (string, error) {
+	payload := map[string]any{
+		"cluster_name": clusterName,
+		"namespace":    namespace,
+		"name":         name,
+		"checkpoint":   checkpoint,
+	}
+	action := "suspend workload"
+	if checkpoint {
+		action = "checkpoint and suspend workload"
+	}
+	return workloadAction(ctx, "POST", "/cluster/workload/suspend", payload, &operation{
+		action:   action,
+		resource: workloadResource(clusterName, namespace, name),
+	})
+}
 
-	var resp *http.Response
-	var err error
+// ResumeWorkload makes a POST request to resume a suspended workload. If
+// fromCheckpoint is set and a checkpoint was taken on suspend, the workload is
+// restored from it instead of starting fresh.
+func ResumeWorkload(ctx context.Context, clusterName, namespace, name string, fromCheckpoint bool) (string, error) {
+	payload := map[string]any{
+		"cluster_name":    clusterName,
+		"namespace":       namespace,
+		"name":            name,
+		"from_checkpoint": fromCheckpoint,
+	}
+	return workloadAction(ctx, "POST", "/cluster/workload/resume", payload, &operation{
+		action:   "resume workload",
+		resource: workloadResource(clusterName, namespace, name),
+	})
+}
 
-	if contentType == "yaml" {
-		resp, err = yamlClientRequest("DELETE", cedanaURL+"/cluster/workload", cedanaAuthToken, payload)
-	} else {
-		resp, err = clientRequest("DELETE", cedanaURL+"/cluster/workload", cedanaAuthToken, payload)
+// SetWorkloadPriority makes a PUT request to change the kueue workload priority class of a workload
+func SetWorkloadPriority(ctx context.Context, clusterName, namespace, name, priorityClass string) (string, error) {
+	payload := map[string]any{
+		"cluster_name":   clusterName,
+		"namespace":      namespace,
+		"name":           name,
+		"priority_class": priorityClass,
 	}
+	return workloadAction(ctx, "PUT", "/cluster/workload/priority", payload, &operation{
+		action:   "set priority " + priorityClass,
+		resource: workloadResource(clusterName, namespace, name),
+	})
+}
 
+func workloadPayloadAction(ctx context.Context, method string, action string, payload []byte, contentType string) (string, error) {
+	ct, err := ParseContentType(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := clientRequest(ctx, request{
+		method:      method,
+		path:        "/cluster/workload",
+		body:        payload,
+		contentType: ct,
+		audit:       &operation{action: action, resource: manifestResource(payload, ct)},
+	})
 	if err != nil {
 		return "", fmt.Errorf("%v", err)
 	}
+	return readResponse(resp)
+}
 
-	defer resp.Body.Close()
-	bodyBytes, err := io.ReadAll(resp.Body)
+func workloadAction(ctx context.Context, method string, path string, payload map[string]any, op *operation) (string, error) {
+	resp, err := clientRequest(ctx, request{
+		method: method,
+		path:   path,
+		body:   payload,
+		audit:  op,
+	})
 	if err != nil {
 		return "", fmt.Errorf("%v", err)
 	}
-	return string(bodyBytes), nil
+	return readResponse(resp)
 }
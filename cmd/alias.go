@@ -0,0 +1,312 @@
+package cmd
+
+// User-defined aliases, shortcuts for commands kept in the config file. An alias
+// is expanded before the command is dispatched, so it can stand for any
+// command, flags included, or for a plugin.
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aliasNameRegex   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	aliasParamsRegex = regexp.MustCompile(`\$(\d+)`)
+)
+
+// Parent alias command
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage aliases, shortcuts for commands you use often",
+	Long: `Manage aliases, shortcuts for commands you use often.
+
+Aliases are kept in the config file. Arguments given after an alias are
+substituted for $1, $2, etc. in its command, and those left over are appended.
+Aliases can not replace built-in commands or plugins.`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <command>",
+	Short: "Create or replace an alias",
+	Long: `Create or replace an alias. The command is quoted as a single argument, and
+is split into arguments like a shell would.`,
+	Example: `  cedana-cli alias set pods 'list pod -c prod-eks -n cedana'
+  cedana-cli alias set pause 'suspend workload $1 -c prod-eks --checkpoint'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, command := args[0], args[1]
+
+		if !aliasNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid alias name %q, must be lowercase letters, digits, - or _", name)
+		}
+		if isCommand(name) {
+			return fmt.Errorf("cannot set alias %s, it would shadow the command of the same name", name)
+		}
+		words, err := splitArgs(command)
+		if err != nil {
+			return fmt.Errorf("invalid command: %w", err)
+		}
+		if len(words) == 0 {
+			return fmt.Errorf("command must not be empty")
+		}
+
+		if err := config.SetAlias(name, command); err != nil {
+			return fmt.Errorf("failed to set alias: %w", err)
+		}
+
+		fmt.Printf("Set alias %s to '%s'\n", name, command)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(config.Global.Aliases) == 0 {
+			fmt.Println("No aliases set")
+			return nil
+		}
+
+		tableWriter := table.NewWriter()
+		tableWriter.SetStyle(style.TableStyle)
+		tableWriter.SetOutputMirror(os.Stdout)
+		tableWriter.Style().Options.SeparateRows = false
+
+		tableWriter.AppendHeader(table.Row{
+			"Alias",
+			"Command",
+		})
+
+		for _, name := range aliasNames() {
+			command := config.Global.Aliases[name]
+			if isCommand(name) {
+				command += style.WarningColors.Sprint(" (shadowed by command)")
+			}
+			tableWriter.AppendRow(table.Row{
+				name,
+				command,
+			})
+		}
+
+		tableWriter.Render()
+		return nil
+	},
+}
+
+var aliasDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an alias",
+	Args:  cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for _, name := range aliasNames() {
+			names = append(names, completion(name, config.Global.Aliases[name]))
+		}
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if _, ok := config.Global.Aliases[name]; !ok {
+			return fmt.Errorf("alias %s is not set", name)
+		}
+		if err := config.DeleteAlias(name); err != nil {
+			return fmt.Errorf("failed to delete alias: %w", err)
+		}
+
+		fmt.Printf("Deleted alias %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasDeleteCmd)
+}
+
+// expandAlias replaces the alias in args, if any, with its command. Aliases
+// are read from the config before flags are parsed, so the global config
+// flags are looked up in args. Commands always take precedence over aliases.
+func expandAlias(root *cobra.Command, args []string) ([]string, error) {
+	i := commandIndex(root, args)
+	if i < 0 {
+		return args, nil
+	}
+	name := args[i]
+
+	if err := config.Init(config.InitArgs{
+		Config:    globalFlagValue(root, args[:i], flags.ConfigFlag.Full),
+		ConfigDir: globalFlagValue(root, args[:i], flags.ConfigDirFlag.Full),
+	}); err != nil {
+		return args, nil // reported once the command runs
+	}
+	command, ok := config.Global.Aliases[name]
+	if !ok || isCommand(name) {
+		return args, nil
+	}
+
+	words, err := splitArgs(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command for alias %s: %w", name, err)
+	}
+
+	rest := args[i+1:]
+	used := make([]bool, len(rest))
+	for j, word := range words {
+		var missing int
+		words[j] = aliasParamsRegex.ReplaceAllStringFunc(word, func(param string) string {
+			n, _ := strconv.Atoi(param[1:])
+			if n < 1 || n > len(rest) {
+				missing = max(missing, n)
+				return param
+			}
+			used[n-1] = true
+			return rest[n-1]
+		})
+		if missing > 0 {
+			return nil, fmt.Errorf("alias %s needs at least %d arguments", name, missing)
+		}
+	}
+
+	// arguments not substituted for a parameter are appended, in order
+	expanded := append([]string{}, args[:i]...)
+	expanded = append(expanded, words...)
+	for j, arg := range rest {
+		if !used[j] {
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+func aliasNames() []string {
+	names := make([]string, 0, len(config.Global.Aliases))
+	for name := range config.Global.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isCommand returns whether name is a built-in command or a plugin
+func isCommand(name string) bool {
+	if isBuiltinCommand(name) {
+		return true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Annotations[PLUGIN_ANNOTATION] != "" && cmd.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// commandIndex returns the index of the first argument in args that isn't a
+// global flag or its value, or -1 if there is none
+func commandIndex(root *cobra.Command, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		if flagTakesValue(root, arg) {
+			i++ // skip the value
+		}
+	}
+	return -1
+}
+
+// globalFlagValue returns the value of the global flag name in args, which
+// must only contain flags
+func globalFlagValue(root *cobra.Command, args []string, name string) string {
+	for i := 0; i < len(args); i++ {
+		flag, value, hasValue := strings.Cut(args[i], "=")
+		if flag != "--"+name {
+			if !hasValue && flagTakesValue(root, args[i]) {
+				i++
+			}
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// splitArgs splits s into arguments like a shell would, honouring single and
+// double quotes and backslash escapes
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var apiMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+var apiCmd = &cobra.Command{
+	Use:   "api <method> <path>",
+	Short: "Make an authenticated request to any endpoint of the Cedana API",
+	Long: `Make an authenticated request to any endpoint of the Cedana API, using the
+configured URL and auth token. Useful for endpoints the CLI has no command for yet.
+
+The request body is read from --file ("-" for stdin), or built as a JSON object
+from --field key=value pairs. For GET, HEAD and DELETE requests, fields are sent
+as query parameters instead. Field values that look like numbers, booleans or
+null are sent as such, everything else as strings.
+
+JSON and YAML responses are pretty-printed. With --paginate, paged responses are
+followed until the last page and their items printed as a single JSON array.
+
+The command exits with an error if the response status is not 2xx, after
+printing the response body.`,
+	Example: `  cedana-cli api GET /cluster
+  cedana-cli api POST /cluster/nodes --field cluster_name=prod
+  cedana-cli api POST /cluster/workload -f job.yaml -H 'X-Request-Id: 42'
+  cedana-cli api GET '/cluster/pods/cedana?limit=100' --paginate`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterCompletions(apiMethods, strings.ToUpper(toComplete)), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method, path := strings.ToUpper(args[0]), args[1]
+		bodyPath, _ := cmd.Flags().GetString(flags.FileFlag.Full)
+		headers, _ := cmd.Flags().GetStringArray(flags.HeaderFlag.Full)
+		fields, _ := cmd.Flags().GetStringArray(flags.FieldFlag.Full)
+		paginate, _ := cmd.Flags().GetBool(flags.PaginateFlag.Full)
+
+		if !isAPIMethod(method) {
+			return fmt.Errorf("invalid method %q, must be one of: %s", args[0], strings.Join(apiMethods, ", "))
+		}
+		if bodyPath != "" && len(fields) > 0 {
+			return fmt.Errorf("cannot use --%s together with --%s", flags.FileFlag.Full, flags.FieldFlag.Full)
+		}
+
+		header, err := parseHeaders(headers)
+		if err != nil {
+			return err
+		}
+		values, err := parseFields(fields)
+		if err != nil {
+			return err
+		}
+
+		var body []byte
+		contentType := client.CONTENT_TYPE_JSON
+		switch {
+		case bodyPath == "-":
+			if body, err = io.ReadAll(os.Stdin); err != nil {
+				return fmt.Errorf("failed to read body from stdin: %w", err)
+			}
+		case bodyPath != "":
+			if body, err = os.ReadFile(bodyPath); err != nil {
+				return fmt.Errorf("failed to read body: %w", err)
+			}
+			contentType = client.ContentTypeFromPath(bodyPath)
+		case len(values) > 0 && (method == "GET" || method == "HEAD" || method == "DELETE"):
+			if path, err = withQuery(path, values); err != nil {
+				return err
+			}
+		case len(values) > 0:
+			if body, err = json.Marshal(values); err != nil {
+				return fmt.Errorf("failed to encode fields: %w", err)
+			}
+		}
+
+		resp, err := client.Raw(cmd.Context(), method, path, body, contentType, header)
+		if err != nil {
+			return err
+		}
+
+		if paginate && resp.OK() {
+			if items, ok := pageItems(resp.Body); ok {
+				return paginateAPI(cmd, method, path, body, contentType, header, resp, items)
+			}
+		}
+
+		printAPIBody(resp)
+		if !resp.OK() {
+			return fmt.Errorf("%s %s failed with status %s", method, path, resp.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+
+	apiCmd.Flags().
+		StringP(flags.FileFlag.Full, flags.FileFlag.Short, "", "file to read the request body from, - for stdin")
+	apiCmd.Flags().
+		StringArrayP(flags.HeaderFlag.Full, flags.HeaderFlag.Short, nil, "add a request header (e.g. 'Accept: application/yaml')")
+	apiCmd.Flags().
+		StringArray(flags.FieldFlag.Full, nil, "add a key=value field to the JSON body, or the query for GET, HEAD and DELETE")
+	apiCmd.Flags().
+		Bool(flags.PaginateFlag.Full, false, "follow continue tokens and print the items of all pages")
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+func isAPIMethod(method string) bool {
+	for _, m := range apiMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHeaders(headers []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: value'", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// parseFields parses key=value pairs, typing values that look like numbers,
+// booleans or null
+func parseFields(fields []string) (map[string]any, error) {
+	values := make(map[string]any)
+	for _, f := range fields {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid field %q, expected key=value", f)
+		}
+		values[key] = fieldValue(value)
+	}
+	return values, nil
+}
+
+func fieldValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// withQuery adds values to the query of path
+func withQuery(path string, values map[string]any) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	query := u.Query()
+	for key, value := range values {
+		if value == nil {
+			query.Set(key, "")
+		} else {
+			query.Set(key, fmt.Sprint(value))
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// pageItems returns the items of a paged JSON response, see client.Page
+func pageItems(body []byte) ([]json.RawMessage, bool) {
+	var page struct {
+		Items    []json.RawMessage `json:"Items"`
+		Continue *string           `json:"Continue"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil || page.Items == nil || page.Continue == nil {
+		return nil, false
+	}
+	return page.Items, true
+}
+
+func pageContinue(body []byte) string {
+	var page struct {
+		Continue string `json:"Continue"`
+	}
+	json.Unmarshal(body, &page)
+	return page.Continue
+}
+
+// paginateAPI follows the continue token of a paged response until the last
+// page, printing the items of all pages as a single array
+func paginateAPI(
+	cmd *cobra.Command,
+	method, path string,
+	body []byte,
+	contentType client.ContentType,
+	header http.Header,
+	resp *client.RawResponse,
+	items []json.RawMessage,
+) error {
+	for token := pageContinue(resp.Body); token != ""; token = pageContinue(resp.Body) {
+		pagePath, err := withQuery(path, map[string]any{"continue": token})
+		if err != nil {
+			return err
+		}
+		resp, err = client.Raw(cmd.Context(), method, pagePath, body, contentType, header)
+		if err != nil {
+			return err
+		}
+		if !resp.OK() {
+			printAPIBody(resp)
+			return fmt.Errorf("%s %s failed with status %s", method, pagePath, resp.Status)
+		}
+		more, ok := pageItems(resp.Body)
+		if !ok {
+			return fmt.Errorf("%s %s did not return a page", method, pagePath)
+		}
+		items = append(items, more...)
+	}
+
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode items: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printAPIBody prints the response body, pretty-printing JSON and YAML
+func printAPIBody(resp *client.RawResponse) {
+	if len(resp.Body) == 0 {
+		return
+	}
+	contentType, err := client.ParseContentType(resp.Header.Get("Content-Type"))
+	if err == nil && contentType == client.CONTENT_TYPE_JSON {
+		var indented bytes.Buffer
+		if json.Indent(&indented, resp.Body, "", "  ") == nil {
+			fmt.Println(indented.String())
+			return
+		}
+	}
+	if err == nil && contentType == client.CONTENT_TYPE_YAML {
+		if indented, ok := indentYAML(resp.Body); ok {
+			os.Stdout.Write(indented)
+			return
+		}
+	}
+	os.Stdout.Write(resp.Body)
+	if !bytes.HasSuffix(resp.Body, []byte("\n")) && contentType != client.CONTENT_TYPE_PROTOBUF {
+		fmt.Println()
+	}
+}
+
+// indentYAML re-encodes YAML documents with an indent of 2, keeping their key
+// order and comments. It reports false if the body is not valid YAML.
+func indentYAML(body []byte) ([]byte, bool) {
+	var indented bytes.Buffer
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	encoder := yaml.NewEncoder(&indented)
+	encoder.SetIndent(2)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, false
+		}
+		if err := encoder.Encode(&document); err != nil {
+			return nil, false
+		}
+	}
+	if err := encoder.Close(); err != nil || indented.Len() == 0 {
+		return nil, false
+	}
+	return indented.Bytes(), true
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/spf13/cobra"
+)
+
+// Parent cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local cache of cluster, namespace, node and pod listings",
+	Long: `Manage the local cache of cluster, namespace, node and pod listings.
+
+Listings are cached under the config directory. Commands always revalidate a
+cached listing with the server, using the ETag it was stored with, if any, so an
+unchanged listing is not downloaded again. Only shell completion uses a cached
+listing as is, without asking the server, until the configured TTL expires.
+Use --no-cache on any command to bypass the cache.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached listings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		count, err := client.ClearCache()
+		if err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		fmt.Printf("Removed %d cached listings from %s\n", count, client.CacheDir())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
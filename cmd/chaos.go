@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	DEFAULT_CHAOS_INTERVAL = 5 * time.Second
+	DEFAULT_CHAOS_TIMEOUT  = 15 * time.Minute
+
+	MIGRATION_PENDING       = "pending"
+	MIGRATION_CHECKPOINTING = "checkpointing"
+	MIGRATION_CHECKPOINTED  = "checkpointed"
+	MIGRATION_RESTORING     = "restoring"
+	MIGRATION_RESTORED      = "restored"
+	MIGRATION_FAILED        = "failed"
+)
+
+// Parent chaos command
+var chaosCmd = &cobra.Command{
+	Use:   "chaos",
+	Short: "Inject failures to test the resilience of workloads",
+}
+
+var chaosInterruptCmd = &cobra.Command{
+	Use:   "interrupt",
+	Short: "Simulate a spot interruption",
+}
+
+var chaosInterruptNodeCmd = &cobra.Command{
+	Use:   "node [name...]",
+	Short: "Simulate a spot-termination notice on nodes and track the migration of their pods",
+	Long: `Simulate a spot-termination notice on nodes and track the migration of their pods.
+
+Nodes can be given by name, or picked at random with --random from the nodes
+matching --selector and --field-selector, which work as they do for list node.
+
+Unless --detach is set, the command waits until every affected pod has been
+restored (or failed) and reports how long checkpoint and restore took. It exits
+with an error if any pod did not survive the interruption.`,
+	Example: `  cedana-cli chaos interrupt node ip-10-0-1-23.ec2.internal -c prod
+  cedana-cli chaos interrupt node --random --count 2 --field-selector instance-type=c5.9xlarge -c prod`,
+	ValidArgsFunction: completeNodes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		random, _ := cmd.Flags().GetBool(flags.RandomFlag.Full)
+		count, _ := cmd.Flags().GetInt(flags.CountFlag.Full)
+		selector, _ := cmd.Flags().GetString(flags.SelectorFlag.Full)
+		fieldSelector, _ := cmd.Flags().GetString(flags.FieldSelectorFlag.Full)
+		detach, _ := cmd.Flags().GetBool(flags.DetachFlag.Full)
+		interval, _ := cmd.Flags().GetDuration(flags.IntervalFlag.Full)
+		timeout, _ := cmd.Flags().GetDuration(flags.TimeoutFlag.Full)
+
+		nodeNames := args
+		switch {
+		case random && len(args) > 0:
+			return fmt.Errorf("cannot specify node names with --%s", flags.RandomFlag.Full)
+		case !random && len(args) == 0:
+			return fmt.Errorf("specify node names or use --%s", flags.RandomFlag.Full)
+		case random:
+			picked, err := pickRandomNodes(cmd.Context(), clusterName, client.ListOptions{
+				Selector:      selector,
+				FieldSelector: fieldSelector,
+			}, count)
+			if err != nil {
+				return err
+			}
+			nodeNames = picked
+		}
+
+		interruption, err := client.InterruptNodes(cmd.Context(), clusterName, nodeNames)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Sent spot-termination notice to %s (interruption %s)\n", strings.Join(nodeNames, ", "), interruption.ID)
+		fmt.Printf("%d pods affected\n", len(interruption.Pods))
+
+		if detach || len(interruption.Pods) == 0 {
+			return nil
+		}
+
+		interruption, err = trackInterruption(cmd, clusterName, interruption, interval, timeout)
+		if err != nil {
+			return err
+		}
+
+		return renderInterruption(interruption)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chaosCmd)
+	chaosCmd.AddCommand(chaosInterruptCmd)
+	chaosInterruptCmd.AddCommand(chaosInterruptNodeCmd)
+
+	chaosCmd.PersistentFlags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	chaosCmd.MarkPersistentFlagRequired(flags.ClusterFlag.Full)
+
+	chaosInterruptNodeCmd.Flags().
+		Bool(flags.RandomFlag.Full, false, "pick nodes at random")
+	chaosInterruptNodeCmd.Flags().
+		Int(flags.CountFlag.Full, 1, "number of nodes to pick with --random")
+	chaosInterruptNodeCmd.Flags().
+		StringP(flags.SelectorFlag.Full, flags.SelectorFlag.Short, "", "only pick nodes matching label selector (e.g. pool=spot,tier!=system)")
+	chaosInterruptNodeCmd.Flags().
+		String(flags.FieldSelectorFlag.Full, "", "only pick nodes matching field selector on "+strings.Join(client.NodeFields.FieldNames(), ", ")+" (e.g. instance-type=c5.9xlarge)")
+	chaosInterruptNodeCmd.Flags().
+		Bool(flags.DetachFlag.Full, false, "do not wait for affected pods to be restored")
+	chaosInterruptNodeCmd.Flags().
+		Duration(flags.IntervalFlag.Full, DEFAULT_CHAOS_INTERVAL, "polling interval while tracking pods")
+	chaosInterruptNodeCmd.Flags().
+		Duration(flags.TimeoutFlag.Full, DEFAULT_CHAOS_TIMEOUT, "maximum time to wait for pods to be restored")
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// pickRandomNodes picks count nodes at random among those matching the
+// selectors of opts, which are applied as they are by list node
+func pickRandomNodes(ctx context.Context, clusterName string, opts client.ListOptions, count int) ([]string, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("--%s must be at least 1", flags.CountFlag.Full)
+	}
+
+	nodes, err := client.GetClusterNodes(ctx, clusterName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		candidates = append(candidates, node.Name)
+	}
+	if len(candidates) < count {
+		return nil, fmt.Errorf("only %d nodes match the selectors, cannot pick %d", len(candidates), count)
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates[:count], nil
+}
+
+// trackInterruption polls the interruption until all affected pods are
+// restored or failed, printing status transitions as they happen
+func trackInterruption(
+	cmd *cobra.Command,
+	clusterName string,
+	interruption *client.Interruption,
+	interval time.Duration,
+	timeout time.Duration,
+) (*client.Interruption, error) {
+	ctx := cmd.Context()
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen := make(map[string]string)
+	for {
+		done := true
+		for _, pod := range interruption.Pods {
+			key := pod.Namespace + "/" + pod.Name
+			if seen[key] != pod.Status {
+				seen[key] = pod.Status
+				fmt.Printf("%s %s: %s\n", time.Now().Format(time.TimeOnly), key, migrationStatus(pod.Status))
+			}
+			if pod.Status != MIGRATION_RESTORED && pod.Status != MIGRATION_FAILED {
+				done = false
+			}
+		}
+		if done {
+			return interruption, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			fmt.Println(style.WarningColors.Sprintf("Timed out after %s waiting for pods to be restored", timeout))
+			return interruption, nil
+		case <-ticker.C:
+		}
+
+		updated, err := client.GetInterruption(ctx, clusterName, interruption.ID)
+		if err != nil {
+			return nil, err
+		}
+		interruption = updated
+	}
+}
+
+func renderInterruption(interruption *client.Interruption) error {
+	fmt.Println()
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetStyle(style.TableStyle)
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.Style().Options.SeparateRows = false
+
+	tableWriter.AppendHeader(table.Row{
+		"Pod",
+		"Namespace",
+		"Node",
+		"Checkpointed",
+		"Restored",
+		"Checkpoint Time",
+		"Restore Time",
+		"Restored On",
+	})
+
+	failed := 0
+	for _, pod := range interruption.Pods {
+		if pod.Status != MIGRATION_RESTORED {
+			failed++
+		}
+		tableWriter.AppendRow(table.Row{
+			pod.Name,
+			pod.Namespace,
+			pod.Node,
+			markStr(pod.CheckpointedAt != nil),
+			markStr(pod.RestoredAt != nil),
+			elapsedStr(&interruption.NoticeAt, pod.CheckpointedAt),
+			elapsedStr(pod.CheckpointedAt, pod.RestoredAt),
+			pod.RestoredNode,
+		})
+		if pod.Error != "" {
+			tableWriter.AppendRow(table.Row{"", "", style.NegativeColors.Sprint(pod.Error)})
+		}
+	}
+
+	tableWriter.Render()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pods did not survive the interruption", failed, len(interruption.Pods))
+	}
+	fmt.Printf("\nAll %d pods survived the interruption\n", len(interruption.Pods))
+	return nil
+}
+
+func migrationStatus(status string) string {
+	switch status {
+	case MIGRATION_RESTORED:
+		return style.PositiveColors.Sprint(status)
+	case MIGRATION_FAILED:
+		return style.NegativeColors.Sprint(status)
+	case MIGRATION_CHECKPOINTED:
+		return style.InfoColors.Sprint(status)
+	default:
+		return status
+	}
+}
+
+func markStr(ok bool) string {
+	if ok {
+		return style.PositiveColors.Sprint(style.TickMark)
+	}
+	return style.NegativeColors.Sprint(style.CrossMark)
+}
+
+func elapsedStr(from, to *time.Time) string {
+	if from == nil || to == nil || from.IsZero() {
+		return style.DashMark
+	}
+	return to.Sub(*from).Round(time.Millisecond).String()
+}
@@ -0,0 +1,164 @@
+package cmd
+
+// Dynamic shell completion of cluster, namespace, node, pod and workload names.
+// Completions query the server, going through the local cache where the
+// listing is cacheable, so they stay fast when pressing TAB repeatedly.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions registers completion of the cluster and namespace flags
+// on every command that defines them. Must be called after all commands and
+// their flags have been added.
+func registerCompletions(cmd *cobra.Command) {
+	for name, complete := range map[string]cobra.CompletionFunc{
+		flags.ClusterFlag.Full:   completeClusters,
+		flags.NamespaceFlag.Full: completeNamespaces,
+	} {
+		if cmd.LocalFlags().Lookup(name) != nil {
+			cmd.RegisterFlagCompletionFunc(name, complete)
+		}
+	}
+	for _, child := range cmd.Commands() {
+		registerCompletions(child)
+	}
+}
+
+func completeClusters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusters, err := client.ListClusters(completionContext(cmd), client.ListOptions{})
+	if err != nil {
+		return completionError(err)
+	}
+	names := make([]string, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		names = append(names, completion(cluster.Name, cluster.Status))
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusterName, ok := completionCluster(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	namespaces, err := client.ListNamespaces(completionContext(cmd), clusterName)
+	if err != nil {
+		return completionError(err)
+	}
+	return filterCompletions(namespaces, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNodes completes any number of node names, skipping those already given
+func completeNodes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusterName, ok := completionCluster(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	nodes, err := client.GetClusterNodes(completionContext(cmd), clusterName, client.ListOptions{})
+	if err != nil {
+		return completionError(err)
+	}
+	given := make(map[string]bool)
+	for _, arg := range args {
+		given[arg] = true
+	}
+	names := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		if !given[node.Name] {
+			names = append(names, completion(node.Name, node.InstanceType))
+		}
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePods completes a single pod name, as the first argument, in the
+// namespace given by flag
+func completePods(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusterName, ok := completionCluster(cmd)
+	if !ok || len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+	pods, err := client.GetClusterPods(completionContext(cmd), clusterName, namespace, client.ListOptions{})
+	if err != nil {
+		return completionError(err)
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, completion(pod.Name, pod.Status))
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkloads completes a single workload name, as the first argument,
+// in the namespace given by flag
+func completeWorkloads(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusterName, ok := completionCluster(cmd)
+	if !ok || len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+	workloads, err := client.GetClusterWorkloads(completionContext(cmd), clusterName, namespace)
+	if err != nil {
+		return completionError(err)
+	}
+	names := make([]string, 0, len(workloads))
+	for _, workload := range workloads {
+		names = append(names, completion(workload.Name, workload.Status))
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// completionContext returns the context for completion requests, which only
+// need names, so may use cached listings without asking the server
+func completionContext(cmd *cobra.Command) context.Context {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return client.AllowStale(ctx)
+}
+
+// completionCluster returns the cluster given by flag, which most completions need
+func completionCluster(cmd *cobra.Command) (string, bool) {
+	clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+	return clusterName, clusterName != ""
+}
+
+// completionError gives up on completing. Errors are only logged, as printing
+// them would garble the shell's completion output.
+func completionError(err error) ([]string, cobra.ShellCompDirective) {
+	log.Debug().Err(err).Msg("completion failed")
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completion formats a completion with a description shown by shells that support it
+func completion(name string, description string) string {
+	if description == "" {
+		return name
+	}
+	return name + "\t" + description
+}
+
+// filterCompletions keeps the completions, possibly followed by a tab and a
+// description, that start with toComplete
+func filterCompletions(completions []string, toComplete string) []string {
+	filtered := make([]string, 0, len(completions))
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, toComplete) {
+			filtered = append(filtered, completion)
+		}
+	}
+	return filtered
+}
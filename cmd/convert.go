@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/cedana/cedana-cli/pkg/workload"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var convertOutputFormats = []string{"yaml", "json"}
+
+// Parent convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert jobs of other schedulers into workloads",
+}
+
+var convertSlurmCmd = &cobra.Command{
+	Use:   "slurm <script>",
+	Short: "Convert a Slurm batch script into a workload",
+	Long: `Convert a Slurm batch script into a workload payload, printed to stdout so it
+can be reviewed and submitted with 'cedana-cli create workload'.
+
+The script runs with bash in the given image. Its #SBATCH directives are mapped
+onto the job:
+
+  --job-name, -J       workload name (default the script file name)
+  --cpus-per-task, -c  cpu request
+  --mem, --mem-per-cpu memory request
+  --time, -t           active deadline of each pod
+  --gres gpu:N, --gpus nvidia.com/gpu limit
+  --array, -a          indexed job, with SLURM_ARRAY_TASK_ID set for each pod
+                       and each index failing on its own
+  --partition, -p      kueue queue label, unless --queue is given
+
+Other directives are ignored with a warning on stderr. Multi-node and
+multi-task jobs are run as a single task.`,
+	Example: `  cedana-cli convert slurm job.sbatch --image gromacs/gromacs:latest -c prod-eks -n cedana > job.yaml
+  cedana-cli create workload --payload job.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		image, _ := cmd.Flags().GetString(flags.ImageFlag.Full)
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		name, _ := cmd.Flags().GetString(flags.NameFlag.Full)
+		queue, _ := cmd.Flags().GetString(flags.QueueFlag.Full)
+		output, _ := cmd.Flags().GetString(flags.OutputFlag.Full)
+
+		if output != "yaml" && output != "json" {
+			return fmt.Errorf("invalid output format %q, must be one of: %s", output, strings.Join(convertOutputFormats, ", "))
+		}
+
+		script, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read script: %w", err)
+		}
+
+		manifest, warnings, err := workload.ConvertSlurm(script, args[0], workload.SlurmOptions{
+			Image:       image,
+			ClusterName: clusterName,
+			Namespace:   namespace,
+			Queue:       queue,
+			Name:        name,
+		})
+		for _, warning := range warnings {
+			fmt.Fprintln(os.Stderr, style.WarningColors.Sprint("Warning: "+warning))
+		}
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(manifest)
+		}
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		return encoder.Encode(manifest)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.AddCommand(convertSlurmCmd)
+
+	convertSlurmCmd.Flags().String(flags.ImageFlag.Full, "", "container image to run the script in")
+	convertSlurmCmd.MarkFlagRequired(flags.ImageFlag.Full)
+	convertSlurmCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	convertSlurmCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+	convertSlurmCmd.Flags().String(flags.NameFlag.Full, "", "workload name (default the job name of the script)")
+	convertSlurmCmd.Flags().String(flags.QueueFlag.Full, "", "kueue local queue (default the partition of the script)")
+	convertSlurmCmd.Flags().
+		StringP(flags.OutputFlag.Full, flags.OutputFlag.Short, "yaml", "output format ("+strings.Join(convertOutputFormats, ", ")+")")
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+const (
+	PRICING_ON_DEMAND = "on-demand"
+	PRICING_SPOT      = "spot"
+)
+
+// costGroupKeys maps supported grouping dimensions to a function that
+// extracts the group key from a cost record.
+var costGroupKeys = map[string]func(r client.CostRecord, labelKey string) string{
+	"cluster":       func(r client.CostRecord, _ string) string { return r.ClusterName },
+	"namespace":     func(r client.CostRecord, _ string) string { return r.Namespace },
+	"workload":      func(r client.CostRecord, _ string) string { return r.Namespace + "/" + r.Workload },
+	"instance-type": func(r client.CostRecord, _ string) string { return r.InstanceType },
+	"region":        func(r client.CostRecord, _ string) string { return r.Region },
+	"label":         func(r client.CostRecord, labelKey string) string { return r.Labels[labelKey] },
+}
+
+var outputFormats = []string{"table", "csv", "json"}
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Report spend grouped by cluster, namespace, workload, instance type or label",
+	Long: `Report spend over a time window grouped by cluster, namespace, workload, instance type,
+region or label, with an on-demand vs spot breakdown and the savings from migrations.
+
+Times for --since and --until can be given as a date (2006-01-02), an RFC3339
+timestamp, or a duration relative to now (e.g. 72h or 30d). By default the
+report covers the current calendar month.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		by, _ := cmd.Flags().GetString(flags.ByFlag.Full)
+		labelKey, _ := cmd.Flags().GetString(flags.LabelKeyFlag.Full)
+		sinceStr, _ := cmd.Flags().GetString(flags.SinceFlag.Full)
+		untilStr, _ := cmd.Flags().GetString(flags.UntilFlag.Full)
+		output, _ := cmd.Flags().GetString(flags.OutputFlag.Full)
+
+		groupKey, ok := costGroupKeys[by]
+		if !ok {
+			return fmt.Errorf("invalid grouping %q, must be one of: %s", by, strings.Join(costGroupings(), ", "))
+		}
+		if by == "label" && labelKey == "" {
+			return fmt.Errorf("--%s is required when grouping by label", flags.LabelKeyFlag.Full)
+		}
+		if err := validateOutputFormat(output); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		until := now
+		var err error
+		if sinceStr != "" {
+			if since, err = parseTime(sinceStr, now); err != nil {
+				return fmt.Errorf("invalid --%s: %w", flags.SinceFlag.Full, err)
+			}
+		}
+		if untilStr != "" {
+			if until, err = parseTime(untilStr, now); err != nil {
+				return fmt.Errorf("invalid --%s: %w", flags.UntilFlag.Full, err)
+			}
+		}
+		if !since.Before(until) {
+			return fmt.Errorf("--%s must be before --%s", flags.SinceFlag.Full, flags.UntilFlag.Full)
+		}
+
+		records, err := client.GetCosts(cmd.Context(), clusterName, since, until)
+		if err != nil {
+			return err
+		}
+
+		groups := groupCosts(records, func(r client.CostRecord) string { return groupKey(r, labelKey) })
+
+		switch output {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(groups)
+		default:
+			header := by
+			if by == "label" {
+				header = labelKey
+			}
+			renderCosts(groups, header, output)
+		}
+
+		if output == "table" {
+			fmt.Printf("\nFrom %s to %s\n", since.Format(time.DateTime), until.Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(costCmd)
+
+	costCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name (default all clusters)")
+	costCmd.Flags().
+		String(flags.ByFlag.Full, "cluster", "group by ("+strings.Join(costGroupings(), ", ")+")")
+	costCmd.Flags().
+		String(flags.LabelKeyFlag.Full, "", "label key to group by when using --by label")
+	costCmd.Flags().
+		String(flags.SinceFlag.Full, "", "start of the window (default start of current month)")
+	costCmd.Flags().
+		String(flags.UntilFlag.Full, "", "end of the window (default now)")
+	costCmd.Flags().
+		StringP(flags.OutputFlag.Full, flags.OutputFlag.Short, "table", "output format ("+strings.Join(outputFormats, ", ")+")")
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// CostGroup is the aggregated cost for a single group of the report
+type CostGroup struct {
+	Group    string  `json:"group"`
+	OnDemand float64 `json:"on_demand"`
+	Spot     float64 `json:"spot"`
+	Total    float64 `json:"total"`
+	Savings  float64 `json:"savings"`
+	Hours    float64 `json:"hours"`
+}
+
+func costGroupings() []string {
+	groupings := make([]string, 0, len(costGroupKeys))
+	for grouping := range costGroupKeys {
+		groupings = append(groupings, grouping)
+	}
+	sort.Strings(groupings)
+	return groupings
+}
+
+// groupCosts aggregates records by the given key, sorted by total cost descending
+func groupCosts(records []client.CostRecord, key func(r client.CostRecord) string) []CostGroup {
+	index := make(map[string]*CostGroup)
+	for _, r := range records {
+		k := key(r)
+		if k == "" {
+			k = "<none>"
+		}
+		g, ok := index[k]
+		if !ok {
+			g = &CostGroup{Group: k}
+			index[k] = g
+		}
+		switch r.PricingModel {
+		case PRICING_SPOT:
+			g.Spot += r.Cost
+		default:
+			g.OnDemand += r.Cost
+		}
+		g.Total += r.Cost
+		g.Savings += r.Savings
+		g.Hours += r.Hours
+	}
+
+	groups := make([]CostGroup, 0, len(index))
+	for _, g := range index {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total == groups[j].Total {
+			return groups[i].Group < groups[j].Group
+		}
+		return groups[i].Total > groups[j].Total
+	})
+	return groups
+}
+
+func renderCosts(groups []CostGroup, header string, output string) {
+	// keep CSV amounts machine-readable
+	formatUSD := formatUSD
+	if output == "csv" {
+		formatUSD = func(amount float64) string { return strconv.FormatFloat(amount, 'f', 2, 64) }
+	}
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetStyle(style.TableStyle)
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.Style().Options.SeparateRows = false
+
+	tableWriter.AppendHeader(table.Row{
+		header,
+		"On-Demand",
+		"Spot",
+		"Total",
+		"Savings",
+		"Hours",
+	})
+
+	var total CostGroup
+	for _, g := range groups {
+		tableWriter.AppendRow(table.Row{
+			g.Group,
+			formatUSD(g.OnDemand),
+			formatUSD(g.Spot),
+			formatUSD(g.Total),
+			formatUSD(g.Savings),
+			strconv.FormatFloat(g.Hours, 'f', 1, 64),
+		})
+		total.OnDemand += g.OnDemand
+		total.Spot += g.Spot
+		total.Total += g.Total
+		total.Savings += g.Savings
+		total.Hours += g.Hours
+	}
+
+	switch output {
+	case "csv":
+		tableWriter.RenderCSV()
+	default:
+		tableWriter.AppendFooter(table.Row{
+			"Total",
+			formatUSD(total.OnDemand),
+			formatUSD(total.Spot),
+			formatUSD(total.Total),
+			style.PositiveColors.Sprint(formatUSD(total.Savings)),
+			strconv.FormatFloat(total.Hours, 'f', 1, 64),
+		})
+		tableWriter.Style().Format.Footer = text.FormatUpper
+		tableWriter.Render()
+	}
+}
+
+func formatUSD(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+func validateOutputFormat(output string) error {
+	for _, f := range outputFormats {
+		if output == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, must be one of: %s", output, strings.Join(outputFormats, ", "))
+}
+
+// parseTime parses an absolute date/timestamp, or a duration relative to now.
+// Durations additionally support a 'd' suffix for days.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid number of days %q", days)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date, RFC3339 timestamp nor duration", s)
+	}
+	return now.Add(-d), nil
+}
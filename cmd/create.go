@@ -27,12 +27,15 @@ var createCmd = &cobra.Command{
 			fmt.Printf("Error retrieving contentType flag: %v\n", err)
 			return
 		}
+		if contentType == "" {
+			contentType = string(client.ContentTypeFromPath(payloadPath))
+		}
 		payloadData, err := os.ReadFile(payloadPath)
 		if err != nil {
 			fmt.Printf("Error reading payload file %s: %v\n", payloadPath, err)
 			return
 		}
-		resp, err := client.CreateWorkload(payloadData, contentType)
+		resp, err := client.CreateWorkload(cmd.Context(), payloadData, contentType)
 
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -59,13 +62,16 @@ var createWorkloadCmd = &cobra.Command{
 			return
 		}
     
+		if contentType == "" {
+			contentType = string(client.ContentTypeFromPath(payloadPath))
+		}
 		payloadData, err := os.ReadFile(payloadPath)
 		if err != nil {
 			fmt.Printf("Error reading payload file %s: %v\n", payloadPath, err)
 			return
 		}
     
-		resp, err := client.CreateWorkload(payloadData, contentType)
+		resp, err := client.CreateWorkload(cmd.Context(), payloadData, contentType)
 
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -84,7 +90,7 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	createWorkloadCmd.PersistentFlags().String("payload", "", "workload payload path")
-	createWorkloadCmd.PersistentFlags().String("contentType", "", "Can be either json or yaml")
+	createWorkloadCmd.PersistentFlags().String("contentType", "", "json, yaml or protobuf (default inferred from payload file extension)")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
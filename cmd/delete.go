@@ -27,13 +27,16 @@ var deleteCmd = &cobra.Command{
 			fmt.Printf("Error retrieving contentType flag: %v\n", err)
 			return
 		}
+		if contentType == "" {
+			contentType = string(client.ContentTypeFromPath(payloadPath))
+		}
 		payloadData, err := os.ReadFile(payloadPath)
 		if err != nil {
 			fmt.Printf("Error reading payload file %s: %v\n", payloadPath, err)
 			return
 		}
     
-		resp, err := client.CreateWorkload(payloadData, contentType)
+		resp, err := client.CreateWorkload(cmd.Context(), payloadData, contentType)
 
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -58,13 +61,16 @@ var deleteWorkloadCmd = &cobra.Command{
 			fmt.Printf("Error retrieving contentType flag: %v\n", err)
 			return
 		}
+		if contentType == "" {
+			contentType = string(client.ContentTypeFromPath(payloadPath))
+		}
 		payloadData, err := os.ReadFile(payloadPath)
 		if err != nil {
 			fmt.Printf("Error reading payload file %s: %v\n", payloadPath, err)
 			return
 		}
     
-		resp, err := client.DeleteWorkload(payloadData, contentType)
+		resp, err := client.DeleteWorkload(cmd.Context(), payloadData, contentType)
 
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -83,7 +89,7 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	deleteWorkloadCmd.PersistentFlags().String("payload", "", "workload payload path")
-	deleteWorkloadCmd.PersistentFlags().String("contentType", "", "Can be either json or yaml")
+	deleteWorkloadCmd.PersistentFlags().String("contentType", "", "json, yaml or protobuf (default inferred from payload file extension)")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
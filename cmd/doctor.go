@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DOCTOR_DIAL_TIMEOUT bounds the DNS lookup and TLS handshake checks
+	DOCTOR_DIAL_TIMEOUT = 10 * time.Second
+	// MAX_CLOCK_SKEW is the clock difference with the server above which tokens may be rejected
+	MAX_CLOCK_SKEW = 30 * time.Second
+	// EXPIRY_WARNING is how long before expiry a token or certificate is reported as expiring soon
+	EXPIRY_WARNING = 7 * 24 * time.Hour
+
+	AUTH_URL = "https://auth.cedana.com"
+)
+
+// configErr is the error from loading the config, if any. Only the doctor
+// command runs despite it, so it can be diagnosed.
+var configErr error
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with your setup",
+	Long: `Diagnose problems with your setup, such as an invalid config file, a
+misconfigured endpoint or an expired auth token.
+
+Checks the config file location, permissions and schema, the environment
+variables overriding it, DNS and TLS reachability of the endpoint, validity and
+expiry of the auth token, clock skew with the server, and compatibility of this
+CLI with the server's API. Each failed check is printed with advice on how to fix it.
+
+The command exits with an error if any check fails. Warnings do not fail it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client.WarningOutput = io.Discard // reported by the API version check
+
+		d := &doctor{}
+		d.checkConfigFile()
+		d.checkEnvOverrides()
+		d.checkToken()
+		if d.checkURL() && d.checkDNS(cmd.Context()) && d.checkTLS(cmd.Context()) && d.checkServer(cmd.Context()) {
+			d.checkClockSkew()
+			d.checkAPIVersion()
+			d.checkCLIVersion()
+		}
+
+		fmt.Println()
+		if d.failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", d.failed, d.total)
+		}
+		if d.warned > 0 {
+			fmt.Printf("All %d checks passed, with %d warnings\n", d.total, d.warned)
+			return nil
+		}
+		fmt.Printf("All %d checks passed\n", d.total)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctor runs checks in order, later checks building on what earlier ones found
+type doctor struct {
+	url      *url.URL
+	resp     *client.RawResponse
+	sentAt   time.Time
+	total    int
+	failed   int
+	warned   int
+	hasToken bool
+}
+
+func (d *doctor) pass(name, detail string) {
+	d.total++
+	fmt.Printf("%s %s: %s\n", style.PositiveColors.Sprint(style.TickMark), name, detail)
+}
+
+func (d *doctor) warn(name, detail, advice string) {
+	d.total++
+	d.warned++
+	fmt.Printf("%s %s: %s\n", style.WarningColors.Sprint(style.CrossMark), name, detail)
+	printAdvice(advice)
+}
+
+func (d *doctor) fail(name, detail, advice string) {
+	d.total++
+	d.failed++
+	fmt.Printf("%s %s: %s\n", style.NegativeColors.Sprint(style.CrossMark), name, detail)
+	printAdvice(advice)
+}
+
+func printAdvice(advice string) {
+	if advice == "" {
+		return
+	}
+	for _, line := range strings.Split(style.BreakLine(advice, style.MAX_LINE_LENGTH-4), "\n") {
+		fmt.Println("    " + style.InfoColors.Sprint(line))
+	}
+}
+
+func (d *doctor) checkConfigFile() {
+	const name = "Config file"
+	path := config.File()
+
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		d.warn(name, fmt.Sprintf("%s does not exist", path),
+			"It is created the first time a command runs without --config. Run any command, or create it manually.")
+	case err != nil:
+		d.fail(name, err.Error(),
+			fmt.Sprintf("Make sure %s is accessible, or use --config-dir to choose another directory.", config.Dir))
+	case info.IsDir():
+		d.fail(name, fmt.Sprintf("%s is a directory", path), "Remove it, it will be recreated on the next run.")
+	default:
+		if f, err := os.OpenFile(path, os.O_RDWR, 0); err != nil {
+			d.fail(name, fmt.Sprintf("%s is not readable and writable: %v", path, err),
+				fmt.Sprintf("Fix its permissions, e.g. chmod %o %s, or make sure you own it.", config.FILE_PERM, path))
+		} else {
+			f.Close()
+			if info.Mode().Perm()&0o077 != 0 {
+				d.warn(name, fmt.Sprintf("%s is accessible by other users (mode %s)", path, info.Mode().Perm()),
+					fmt.Sprintf("It holds your auth token. Restrict it to your user with chmod %o %s.", config.FILE_PERM, path))
+			} else {
+				d.pass(name, fmt.Sprintf("%s (mode %s)", path, info.Mode().Perm()))
+			}
+		}
+	}
+
+	if configErr != nil {
+		d.fail("Config schema", configErr.Error(),
+			fmt.Sprintf("Fix or remove the offending keys, or delete %s to recreate it with defaults.", path))
+	} else {
+		d.pass("Config schema", "valid")
+	}
+}
+
+func (d *doctor) checkEnvOverrides() {
+	const name = "Env overrides"
+	overrides := config.EnvOverrides()
+	if len(overrides) == 0 {
+		d.pass(name, "none, all settings come from the config file or defaults")
+		return
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	d.pass(name, fmt.Sprintf("%d settings overridden by the environment", len(keys)))
+	for _, key := range keys {
+		fmt.Printf("    %s %s %s %s\n", style.BulletMark, key, style.DashMark, overrides[key])
+	}
+}
+
+func (d *doctor) checkURL() bool {
+	const name = "Endpoint URL"
+	raw := config.Global.Connection.URL
+	if raw == "" {
+		d.fail(name, "not set",
+			`Set CEDANA_URL to your organization's endpoint, e.g. export CEDANA_URL="https://<org-name>.cedana.ai/v1".`)
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		d.fail(name, fmt.Sprintf("%q is not a valid http(s) URL", raw),
+			`Set CEDANA_URL to your organization's endpoint, e.g. export CEDANA_URL="https://<org-name>.cedana.ai/v1".`)
+		return false
+	}
+	d.url = u
+	if u.Scheme == "http" {
+		d.warn(name, raw+" is not encrypted", "Your auth token is sent in clear text. Use an https:// URL.")
+		return true
+	}
+	d.pass(name, raw)
+	return true
+}
+
+func (d *doctor) checkDNS(ctx context.Context) bool {
+	const name = "DNS"
+	ctx, cancel := context.WithTimeout(ctx, DOCTOR_DIAL_TIMEOUT)
+	defer cancel()
+
+	host := d.url.Hostname()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		d.fail(name, fmt.Sprintf("cannot resolve %s: %v", host, err),
+			"Check the endpoint URL for typos, and your network or VPN connection.")
+		return false
+	}
+	d.pass(name, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")))
+	return true
+}
+
+func (d *doctor) checkTLS(ctx context.Context) bool {
+	const name = "TLS"
+	ctx, cancel := context.WithTimeout(ctx, DOCTOR_DIAL_TIMEOUT)
+	defer cancel()
+
+	port := d.url.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[d.url.Scheme]
+	}
+	addr := net.JoinHostPort(d.url.Hostname(), port)
+
+	if d.url.Scheme == "http" {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			d.fail("Connection", fmt.Sprintf("cannot connect to %s: %v", addr, err),
+				"Check the endpoint URL, and that no firewall or proxy blocks it.")
+			return false
+		}
+		conn.Close()
+		d.pass("Connection", "connected to "+addr)
+		return true
+	}
+
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: d.url.Hostname()}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		d.fail(name, fmt.Sprintf("cannot connect to %s: %v", addr, err),
+			"Check the endpoint URL, that no firewall or proxy blocks it, and that your system clock and CA certificates are up to date.")
+		return false
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	expiry := state.PeerCertificates[0].NotAfter
+	if time.Until(expiry) < EXPIRY_WARNING {
+		d.warn(name, fmt.Sprintf("certificate of %s expires on %s", addr, expiry.Format(time.DateOnly)),
+			"Contact Cedana support if it is not renewed soon.")
+		return true
+	}
+	d.pass(name, fmt.Sprintf("%s, certificate valid until %s", tls.VersionName(state.Version), expiry.Format(time.DateOnly)))
+	return true
+}
+
+func (d *doctor) checkToken() {
+	const name = "Auth token"
+	token := config.Global.Connection.AuthToken
+	if token == "" {
+		d.fail(name, "not set",
+			fmt.Sprintf("Create an API key at %s and export CEDANA_AUTH_TOKEN=<your key>.", AUTH_URL))
+		return
+	}
+	d.hasToken = true
+
+	expiry, ok := tokenExpiry(token)
+	switch {
+	case !ok:
+		d.pass(name, "set")
+	case time.Now().After(expiry):
+		d.fail(name, fmt.Sprintf("expired on %s", expiry.Format(time.DateTime)),
+			fmt.Sprintf("Create a new API key at %s and update CEDANA_AUTH_TOKEN.", AUTH_URL))
+	case time.Until(expiry) < EXPIRY_WARNING:
+		d.warn(name, fmt.Sprintf("expires on %s", expiry.Format(time.DateTime)),
+			fmt.Sprintf("Create a new API key at %s before it expires.", AUTH_URL))
+	default:
+		d.pass(name, fmt.Sprintf("set, expires on %s", expiry.Format(time.DateOnly)))
+	}
+}
+
+// tokenExpiry returns the expiry of a JWT token, read without verifying its
+// signature. Tokens that are not JWTs, or have no expiry, return false.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+func (d *doctor) checkServer(ctx context.Context) bool {
+	const name = "Server"
+	if !d.hasToken {
+		return false
+	}
+
+	d.sentAt = time.Now()
+	resp, err := client.Raw(ctx, http.MethodGet, "/cluster", nil, client.CONTENT_TYPE_JSON, nil)
+	if err != nil {
+		d.fail(name, err.Error(), "Check the endpoint URL, and that no firewall or proxy blocks it.")
+		return false
+	}
+	d.resp = resp
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		d.fail(name, "auth token rejected with "+resp.Status,
+			fmt.Sprintf("Make sure the token belongs to this endpoint's organization, or create a new API key at %s.", AUTH_URL))
+	case resp.StatusCode == http.StatusNotFound:
+		d.fail(name, "endpoint not found ("+resp.Status+")",
+			"Check the path of the endpoint URL, it usually ends with the API version, e.g. /v1.")
+	case !resp.OK():
+		d.fail(name, "request failed with "+resp.Status, "The server may be having issues, try again later.")
+	default:
+		d.pass(name, "reachable, auth token accepted")
+	}
+	return true
+}
+
+func (d *doctor) checkClockSkew() {
+	const name = "Clock"
+	date, err := http.ParseTime(d.resp.Header.Get("Date"))
+	if err != nil {
+		return // server sent no date to compare with
+	}
+
+	// The server's date has second precision, and was set somewhere between
+	// sending the request and now
+	skew := time.Since(d.sentAt)/2 + d.sentAt.Sub(date)
+	skew = skew.Round(time.Second)
+	if skew.Abs() > MAX_CLOCK_SKEW {
+		d.warn(name, fmt.Sprintf("local clock is off by %s from the server's", skew),
+			"Tokens may be rejected as expired or not yet valid. Enable time synchronization, e.g. NTP.")
+		return
+	}
+	d.pass(name, "in sync with the server")
+}
+
+func (d *doctor) checkAPIVersion() {
+	const name = "API version"
+	serverVersion := d.resp.Header.Get(client.API_VERSION_HEADER)
+	if serverVersion == "" {
+		d.pass(name, fmt.Sprintf("CLI speaks %s, server did not report its version", client.API_VERSION))
+		return
+	}
+	newer, err := client.NewerVersion(serverVersion, client.API_VERSION)
+	if err != nil {
+		d.warn(name, fmt.Sprintf("server reported an invalid version %q", serverVersion), "")
+		return
+	}
+	if newer {
+		d.warn(name, fmt.Sprintf("server speaks %s, newer than this CLI's %s", serverVersion, client.API_VERSION),
+			"Some fields may be missing from output. Upgrade cedana-cli.")
+		return
+	}
+	d.pass(name, fmt.Sprintf("CLI speaks %s, server speaks %s", client.API_VERSION, serverVersion))
+}
+
+func (d *doctor) checkCLIVersion() {
+	const name = "CLI version"
+	minVersion := d.resp.Header.Get(client.MIN_CLI_VERSION_HEADER)
+	switch {
+	case minVersion == "":
+		d.pass(name, versionInfo.version+", server did not report a minimum version")
+	case !client.CLISupported(minVersion):
+		d.fail(name, fmt.Sprintf("%s is older than the minimum %s supported by the server", versionInfo.version, minVersion),
+			"Upgrade cedana-cli to the latest release.")
+	default:
+		d.pass(name, fmt.Sprintf("%s, server supports %s or newer", versionInfo.version, minVersion))
+	}
+}
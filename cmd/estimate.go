@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/cedana/cedana-cli/pkg/workload"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate cost and capacity for a batch of workloads before submitting it",
+	Long: `Estimate cost and capacity for a batch of workloads before submitting it.
+
+The resource requests of the workload manifest are matched against the instance
+types of the cluster's nodepools to project the number of nodes and hourly cost
+range, and against the quota of the workload's kueue cluster queue to project
+how many jobs are admitted at once. If a per-job --duration is given (or the job
+sets activeDeadlineSeconds), the wall-clock time and total cost of the batch are
+estimated as well.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString(flags.FileFlag.Full)
+		count, _ := cmd.Flags().GetInt(flags.CountFlag.Full)
+		duration, _ := cmd.Flags().GetDuration(flags.DurationFlag.Full)
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+
+		if count < 1 {
+			return fmt.Errorf("--%s must be at least 1", flags.CountFlag.Full)
+		}
+
+		manifest, err := workload.LoadManifest(path)
+		if err != nil {
+			return err
+		}
+		if clusterName == "" {
+			clusterName = manifest.ClusterName
+		}
+		if clusterName == "" {
+			return fmt.Errorf("no cluster_name in manifest, please specify --%s", flags.ClusterFlag.Full)
+		}
+
+		job := &manifest.Workload
+		podResources, err := job.PodResources()
+		if err != nil {
+			return err
+		}
+		if podResources.CPU == 0 && podResources.Memory == 0 && podResources.GPU == 0 {
+			return fmt.Errorf("workload %s requests no resources, cannot estimate", job.Metadata.Name)
+		}
+		if duration == 0 && job.Spec.ActiveDeadlineSeconds != nil {
+			duration = time.Duration(*job.Spec.ActiveDeadlineSeconds) * time.Second
+		}
+
+		nodePools, err := client.GetClusterNodePools(cmd.Context(), clusterName)
+		if err != nil {
+			return err
+		}
+		queues, err := client.GetClusterQueues(cmd.Context(), clusterName)
+		if err != nil {
+			return err
+		}
+
+		est := estimate(job, podResources, count, duration, nodePools, queues)
+		renderEstimate(job, clusterName, podResources, est)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+
+	estimateCmd.Flags().
+		StringP(flags.FileFlag.Full, flags.FileFlag.Short, "", "workload manifest path (json or yaml)")
+	estimateCmd.MarkFlagRequired(flags.FileFlag.Full)
+	estimateCmd.MarkFlagFilename(flags.FileFlag.Full, "json", "yaml", "yml")
+	estimateCmd.Flags().
+		Int(flags.CountFlag.Full, 1, "number of copies of the workload to submit")
+	estimateCmd.Flags().
+		Duration(flags.DurationFlag.Full, 0, "expected run time of a single job (default activeDeadlineSeconds)")
+	estimateCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name (default cluster_name from manifest)")
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+type candidate struct {
+	nodePool     string
+	instanceType client.InstanceType
+	podsPerNode  int
+	nodes        int
+	hourlyLow    float64 // cheapest allowed capacity type
+	hourlyHigh   float64 // on-demand
+}
+
+type estimation struct {
+	count       int
+	totalPods   int
+	queueName   string
+	queue       *client.ClusterQueue
+	admittedNow int // jobs admitted immediately given current queue usage
+	perWave     int // jobs running concurrently at full quota
+	waves       int
+	duration    time.Duration
+	wallClock   time.Duration
+	candidates  []candidate
+}
+
+func estimate(
+	job *workload.Job,
+	podResources workload.Resources,
+	count int,
+	duration time.Duration,
+	nodePools []client.NodePool,
+	queues []client.ClusterQueue,
+) estimation {
+	parallelism := job.Parallelism()
+	completions := job.Completions()
+
+	est := estimation{
+		count:     count,
+		totalPods: count * completions,
+		queueName: job.Metadata.Labels[workload.QUEUE_NAME_LABEL],
+		duration:  duration,
+	}
+
+	// Kueue admits whole jobs, so capacity is counted in jobs
+	jobResources := workload.Resources{
+		CPU:    podResources.CPU * float64(parallelism),
+		Memory: podResources.Memory * float64(parallelism),
+		GPU:    podResources.GPU * float64(parallelism),
+	}
+
+	est.admittedNow, est.perWave = count, count
+	for i := range queues {
+		if slices.Contains(queues[i].LocalQueues, est.queueName) {
+			q := &queues[i]
+			est.queue = q
+			est.perWave = min(count, fits(workload.Resources{
+				CPU:    q.CPUQuota,
+				Memory: float64(q.MemoryQuota),
+				GPU:    q.GPUQuota,
+			}, jobResources))
+			est.admittedNow = min(count, fits(workload.Resources{
+				CPU:    q.CPUQuota - q.CPUUsed,
+				Memory: float64(q.MemoryQuota - q.MemoryUsed),
+				GPU:    q.GPUQuota - q.GPUUsed,
+			}, jobResources))
+			break
+		}
+	}
+
+	if est.perWave > 0 {
+		est.waves = int(math.Ceil(float64(count) / float64(est.perWave)))
+		rounds := int(math.Ceil(float64(completions) / float64(parallelism)))
+		est.wallClock = time.Duration(est.waves*rounds) * duration
+	}
+
+	concurrentPods := est.perWave * parallelism
+	for _, pool := range nodePools {
+		spotAllowed := len(pool.CapacityTypes) == 0 || slices.Contains(pool.CapacityTypes, PRICING_SPOT)
+		for _, it := range pool.InstanceTypes {
+			podsPerNode := fits(workload.Resources{
+				CPU:    it.CPU,
+				Memory: float64(it.Memory),
+				GPU:    it.GPU,
+			}, podResources)
+			if podsPerNode == 0 {
+				continue
+			}
+			nodes := int(math.Ceil(float64(concurrentPods) / float64(podsPerNode)))
+			c := candidate{
+				nodePool:     pool.Name,
+				instanceType: it,
+				podsPerNode:  podsPerNode,
+				nodes:        nodes,
+				hourlyHigh:   float64(nodes) * it.OnDemandPrice,
+			}
+			c.hourlyLow = c.hourlyHigh
+			if spotAllowed && it.SpotPrice > 0 {
+				c.hourlyLow = float64(nodes) * it.SpotPrice
+			}
+			est.candidates = append(est.candidates, c)
+		}
+	}
+	sort.Slice(est.candidates, func(i, j int) bool {
+		return est.candidates[i].hourlyLow < est.candidates[j].hourlyLow
+	})
+
+	return est
+}
+
+// fits returns how many times the requested resources fit in the available ones
+func fits(available workload.Resources, requested workload.Resources) int {
+	n := math.MaxInt
+	for _, pair := range [][2]float64{
+		{available.CPU, requested.CPU},
+		{available.Memory, requested.Memory},
+		{available.GPU, requested.GPU},
+	} {
+		if pair[1] <= 0 {
+			continue
+		}
+		n = min(n, int(math.Floor(max(pair[0], 0)/pair[1])))
+	}
+	return n
+}
+
+func renderEstimate(job *workload.Job, clusterName string, podResources workload.Resources, est estimation) {
+	summary := table.NewWriter()
+	summary.SetStyle(style.TableStyle)
+	summary.SetOutputMirror(os.Stdout)
+
+	summary.AppendRow(table.Row{"Workload", fmt.Sprintf("%s (namespace %s)", job.Metadata.Name, job.Metadata.Namespace)})
+	summary.AppendRow(table.Row{"Cluster", clusterName})
+	summary.AppendRow(table.Row{"Per pod", formatResources(podResources)})
+	summary.AppendRow(table.Row{"Batch", fmt.Sprintf("%d jobs, %d pods in total", est.count, est.totalPods)})
+
+	switch {
+	case est.queueName == "":
+		summary.AppendRow(table.Row{"Queue", style.WarningColors.Sprint("no kueue queue label, admission is not limited by a quota")})
+	case est.queue == nil:
+		summary.AppendRow(table.Row{"Queue", style.WarningColors.Sprintf("local queue %s not found in any cluster queue", est.queueName)})
+	default:
+		q := est.queue
+		summary.AppendRow(table.Row{"Queue", fmt.Sprintf("%s in cluster queue %s (%d admitted, %d pending)",
+			est.queueName, q.Name, q.AdmittedWorkloads, q.PendingWorkloads)})
+		summary.AppendRow(table.Row{"Quota", fmt.Sprintf("%s CPU, %s memory, %.0f GPU (in use: %s CPU, %s memory, %.0f GPU)",
+			formatCPU(q.CPUQuota), formatBytes(q.MemoryQuota), q.GPUQuota,
+			formatCPU(q.CPUUsed), formatBytes(q.MemoryUsed), q.GPUUsed)})
+	}
+
+	admission := fmt.Sprintf("%d admitted immediately, %d queued", est.admittedNow, est.count-est.admittedNow)
+	if est.perWave == 0 {
+		admission = style.NegativeColors.Sprint("a single job exceeds the queue quota and will never be admitted")
+	} else if est.admittedNow < est.count {
+		admission = style.WarningColors.Sprint(admission) + fmt.Sprintf("; up to %d run concurrently", est.perWave)
+	}
+	summary.AppendRow(table.Row{"Admission", admission})
+
+	if est.perWave > 0 {
+		if est.duration > 0 {
+			summary.AppendRow(table.Row{"Wall-clock", fmt.Sprintf("~%s (%d waves of %s)", est.wallClock, est.waves, est.duration)})
+		} else {
+			summary.AppendRow(table.Row{"Wall-clock", style.DisabledColors.Sprintf("%d waves, specify --%s to estimate time", est.waves, flags.DurationFlag.Full)})
+		}
+	}
+
+	if len(est.candidates) == 0 {
+		summary.AppendRow(table.Row{"Capacity", style.NegativeColors.Sprint("no instance type in the cluster's nodepools can fit a single pod")})
+		summary.Render()
+		return
+	}
+
+	// both ends of the cost range are those of the cheapest candidate, from
+	// its cheapest allowed capacity type up to on-demand
+	best := est.candidates[0]
+	summary.AppendRow(table.Row{"Nodes", fmt.Sprintf("%d x %s (cheapest)", best.nodes, best.instanceType.Name)})
+	summary.AppendRow(table.Row{"Hourly cost", costRange(best.hourlyLow, best.hourlyHigh)})
+	if est.wallClock > 0 {
+		hours := est.wallClock.Hours()
+		summary.AppendRow(table.Row{"Batch cost", costRange(best.hourlyLow*hours, best.hourlyHigh*hours)})
+	}
+	summary.Render()
+
+	fmt.Println()
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetStyle(style.TableStyle)
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.Style().Options.SeparateRows = false
+
+	tableWriter.AppendHeader(table.Row{
+		"Nodepool",
+		"Instance Type",
+		"Pods/Node",
+		"Nodes",
+		"Min $/hr",
+		"On-Demand $/hr",
+	})
+	for _, c := range est.candidates {
+		tableWriter.AppendRow(table.Row{
+			c.nodePool,
+			c.instanceType.Name,
+			c.podsPerNode,
+			c.nodes,
+			formatUSD(c.hourlyLow),
+			formatUSD(c.hourlyHigh),
+		})
+	}
+	tableWriter.Render()
+}
+
+func formatResources(r workload.Resources) string {
+	s := fmt.Sprintf("%s CPU, %s memory", formatCPU(r.CPU), formatBytes(int64(r.Memory)))
+	if r.GPU > 0 {
+		s += fmt.Sprintf(", %.0f GPU", r.GPU)
+	}
+	return s
+}
+
+// costRange formats the cost of a candidate on spot and on-demand capacity,
+// or only on-demand if spot is not allowed or has no price
+func costRange(low, high float64) string {
+	if low == high {
+		return formatUSD(high) + " (on-demand)"
+	}
+	return fmt.Sprintf("%s (spot) - %s (on-demand)", formatUSD(low), formatUSD(high))
+}
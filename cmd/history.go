@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/audit"
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// AUDIT_REDACTED replaces secrets in the command lines recorded in the audit log
+const AUDIT_REDACTED = "<redacted>"
+
+var historyOutputFormats = []string{"table", "json"}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the audit log of mutating operations made from this machine",
+	Long: `Show the audit log of mutating operations made from this machine, such as
+creating, deleting, suspending or checkpointing workloads, oldest first.
+
+Each operation is recorded with its time, the local user (and the user that
+invoked sudo, if any), the command line, the target resource and its result.
+The log is append-only, kept under the config directory, and can be disabled
+with audit.enabled in the config. Set audit.ship to also send each record to
+the server.
+
+Times for --since can be given as a date (2006-01-02), an RFC3339 timestamp,
+or a duration relative to now (e.g. 72h or 30d). --resource matches any
+resource containing the given string, e.g. a cluster, namespace or name.`,
+	Example: `  cedana-cli history --since 24h
+  cedana-cli history --resource prod-eks/cedana/train-42`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceStr, _ := cmd.Flags().GetString(flags.SinceFlag.Full)
+		resource, _ := cmd.Flags().GetString(flags.ResourceFlag.Full)
+		output, _ := cmd.Flags().GetString(flags.OutputFlag.Full)
+
+		if output != "table" && output != "json" {
+			return fmt.Errorf("invalid output format %q, must be one of: %s", output, strings.Join(historyOutputFormats, ", "))
+		}
+
+		filter := audit.Filter{Resource: resource}
+		if sinceStr != "" {
+			since, err := parseTime(sinceStr, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", flags.SinceFlag.Full, err)
+			}
+			filter.Since = since
+		}
+
+		entries, err := audit.Read(config.Dir, filter)
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if entries == nil {
+				entries = []audit.Entry{}
+			}
+			return encoder.Encode(entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No operations recorded")
+			return nil
+		}
+
+		tableWriter := table.NewWriter()
+		tableWriter.SetStyle(style.TableStyle)
+		tableWriter.SetOutputMirror(os.Stdout)
+		tableWriter.Style().Options.SeparateRows = false
+
+		tableWriter.AppendHeader(table.Row{
+			"Time",
+			"User",
+			"Action",
+			"Resource",
+			"Result",
+			"Command",
+		})
+		for _, entry := range entries {
+			result := style.PositiveColors.Sprint(entry.Result)
+			if entry.Result != "ok" {
+				result = style.NegativeColors.Sprint(style.BreakLine(entry.Result, style.MAX_LINE_LENGTH/2))
+			}
+			tableWriter.AppendRow(table.Row{
+				entry.Time.Local().Format(time.DateTime),
+				entry.User,
+				entry.Action,
+				entry.Resource,
+				result,
+				entry.Command,
+			})
+		}
+		tableWriter.Render()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().String(flags.SinceFlag.Full, "", "only show operations since this time")
+	historyCmd.Flags().String(flags.ResourceFlag.Full, "", "only show operations on resources containing this string")
+	historyCmd.Flags().
+		StringP(flags.OutputFlag.Full, flags.OutputFlag.Short, "table", "output format ("+strings.Join(historyOutputFormats, ", ")+")")
+}
+
+// auditCommand returns the command line to record in the audit log, with
+// secrets redacted: the value of --config, which may hold an auth token, the
+// values of request headers, and the values of request fields whose keys are in
+// trace.redact_fields
+func auditCommand(name string, args []string) string {
+	redactors := map[string]func(string) string{
+		flags.ConfigFlag.Full: func(string) string { return AUDIT_REDACTED },
+		flags.HeaderFlag.Full: redactHeader,
+		flags.FieldFlag.Full:  redactField,
+	}
+	shorthands := map[string]string{
+		flags.HeaderFlag.Short: flags.HeaderFlag.Full,
+	}
+
+	words := []string{name}
+	var redactNext func(string) string
+	for _, arg := range args {
+		switch {
+		case redactNext != nil:
+			arg = redactNext(arg)
+			redactNext = nil
+		case strings.HasPrefix(arg, "--"):
+			flag, value, attached := strings.Cut(arg[2:], "=")
+			if redact, ok := redactors[flag]; ok {
+				if attached {
+					arg = "--" + flag + "=" + redact(value)
+				} else {
+					redactNext = redact
+				}
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) >= 2:
+			if flag, ok := shorthands[arg[1:2]]; ok {
+				if value := strings.TrimPrefix(arg[2:], "="); value != "" {
+					arg = arg[:2] + redactors[flag](value)
+				} else {
+					redactNext = redactors[flag]
+				}
+			}
+		}
+		if strings.ContainsAny(arg, " \t\"'") {
+			arg = strconv.Quote(arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// redactHeader redacts the value of a "Name: value" header, keeping its name
+func redactHeader(header string) string {
+	name, _, ok := strings.Cut(header, ":")
+	if !ok {
+		return AUDIT_REDACTED
+	}
+	return name + ": " + AUDIT_REDACTED
+}
+
+// redactField redacts the value of a key=value field if its key is one of
+// trace.redact_fields
+func redactField(field string) string {
+	key, _, ok := strings.Cut(field, "=")
+	if !ok {
+		return field
+	}
+	for _, redacted := range config.Global.Trace.RedactFields {
+		if strings.EqualFold(key, redacted) {
+			return key + "=" + AUDIT_REDACTED
+		}
+	}
+	return field
+}
@@ -6,10 +6,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/cedana/cedana-cli/client"
 	"github.com/cedana/cedana-cli/pkg/flags"
-	"github.com/cedana/cedana/pkg/style"
+	"github.com/cedana/cedana-cli/pkg/style"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -28,17 +29,18 @@ var listClusterCmd = &cobra.Command{
 	Use:   "cluster",
 	Short: "List all active managed clusters for the organization",
 	Run: func(cmd *cobra.Command, args []string) {
-		clusters, err := client.ListClusters()
+		clusters, err := client.ListClusters(cmd.Context(), listOptions(cmd))
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
 		// TODO pretty print
-		fmt.Printf("Found %d clusters:\n", len(clusters))
-		for _, cluster := range clusters {
+		fmt.Printf("Found %d clusters:\n", len(clusters.Items))
+		for _, cluster := range clusters.Items {
 			fmt.Printf("- %s: %s\n", cluster.Name, cluster.ID)
 		}
+		printContinue(clusters.Continue, clusters.Truncated)
 	},
 }
 
@@ -53,10 +55,11 @@ var listNodeCmd = &cobra.Command{
 			return fmt.Errorf("failed to get cluster flag: %w", err)
 		}
 
-		nodes, err := client.GetClusterNodes(clusterName)
+		page, err := client.GetClusterNodes(cmd.Context(), clusterName, listOptions(cmd))
 		if err != nil {
 			return err
 		}
+		nodes := page.Items
 
 		if len(nodes) == 0 {
 			fmt.Println("No nodes to show")
@@ -85,6 +88,30 @@ var listNodeCmd = &cobra.Command{
 		tableWriter.Render()
 
 		fmt.Printf("\n%d nodes found\n", len(nodes))
+		printContinue(page.Continue, page.Truncated)
+		return nil
+	},
+}
+
+var listNamespaceCmd = &cobra.Command{
+	Use:   "namespace",
+	Short: "List all namespaces of a cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, err := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster flag: %w", err)
+		}
+
+		namespaces, err := client.ListNamespaces(cmd.Context(), clusterName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Found %d namespaces:\n", len(namespaces))
+		for _, namespace := range namespaces {
+			fmt.Printf("- %s\n", namespace)
+		}
 		return nil
 	},
 }
@@ -105,13 +132,13 @@ var listPodCmd = &cobra.Command{
 			fmt.Printf("Error retrieving namespace flag: %v\n", err)
 			return
 		}
-		pods, err := client.GetClusterPods(clusterName, clusterNamespace)
+		pods, err := client.GetClusterPods(cmd.Context(), clusterName, clusterNamespace, listOptions(cmd))
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
-		fmt.Printf("Found %d pods in namespace %s :\n", len(pods), clusterNamespace)
-		for _, pod := range pods {
+		fmt.Printf("Found %d pods in namespace %s :\n", len(pods.Items), clusterNamespace)
+		for _, pod := range pods.Items {
 			fmt.Printf("%s : %s\n",
 				pod.Name,
 				//		pod.ID,
@@ -120,6 +147,7 @@ var listPodCmd = &cobra.Command{
 				//		pod.NodeID,
 			)
 		}
+		printContinue(pods.Continue, pods.Truncated)
 	},
 }
 
@@ -128,14 +156,64 @@ func init() {
 	listCmd.AddCommand(listPodCmd)
 	listCmd.AddCommand(listClusterCmd)
 	listCmd.AddCommand(listNodeCmd)
+	listCmd.AddCommand(listNamespaceCmd)
 
 	listPodCmd.PersistentFlags().
 		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
 	listNodeCmd.PersistentFlags().
 		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	listNamespaceCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	listNamespaceCmd.MarkFlagRequired(flags.ClusterFlag.Full)
 
 	listPodCmd.PersistentFlags().
 		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "", "namespace")
 	listNodeCmd.PersistentFlags().
 		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "", "namespace")
+
+	addListFlags(listClusterCmd, client.ClusterFields.FieldNames(), client.ClusterFields.SortNames())
+	addListFlags(listNodeCmd, client.NodeFields.FieldNames(), client.NodeFields.SortNames())
+	addListFlags(listPodCmd, client.PodFields.FieldNames(), client.PodFields.SortNames())
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+func addListFlags(cmd *cobra.Command, fieldNames []string, sortNames []string) {
+	cmd.Flags().
+		Int(flags.LimitFlag.Full, 0, "maximum number of items to list, 0 for all")
+	cmd.Flags().
+		String(flags.ContinueFlag.Full, "", "continue a previous limited list from the token it printed")
+	cmd.Flags().
+		StringP(flags.SelectorFlag.Full, flags.SelectorFlag.Short, "", "label selector (e.g. app=gromacs,tier!=batch)")
+	cmd.Flags().
+		String(flags.FieldSelectorFlag.Full, "", "field selector on "+strings.Join(fieldNames, ", ")+" (e.g. status=Running)")
+	cmd.Flags().
+		String(flags.SortByFlag.Full, "", "field to sort by ("+strings.Join(sortNames, ", ")+")")
+}
+
+func listOptions(cmd *cobra.Command) client.ListOptions {
+	limit, _ := cmd.Flags().GetInt(flags.LimitFlag.Full)
+	continueToken, _ := cmd.Flags().GetString(flags.ContinueFlag.Full)
+	selector, _ := cmd.Flags().GetString(flags.SelectorFlag.Full)
+	fieldSelector, _ := cmd.Flags().GetString(flags.FieldSelectorFlag.Full)
+	sortBy, _ := cmd.Flags().GetString(flags.SortByFlag.Full)
+
+	return client.ListOptions{
+		Limit:         limit,
+		Continue:      continueToken,
+		Selector:      selector,
+		FieldSelector: fieldSelector,
+		SortBy:        sortBy,
+	}
+}
+
+// printContinue tells how to list the next items, if there are any
+func printContinue(token string, truncated bool) {
+	if token != "" {
+		fmt.Printf("\nMore items available, list them with --%s %s\n", flags.ContinueFlag.Full, token)
+	} else if truncated {
+		fmt.Printf("\nMore items available, raise --%s to list them\n", flags.LimitFlag.Full)
+	}
 }
@@ -0,0 +1,475 @@
+package cmd
+
+// External plugins, in the style of kubectl and gh. Any executable named
+// cedana-cli-<name> in the plugins directory or on PATH becomes the command
+// `cedana-cli <name>`. Plugins receive all arguments after their name as is,
+// and the resolved connection and output settings through the environment.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/logging"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	PLUGIN_PREFIX    = "cedana-cli-"
+	PLUGIN_FILE_PERM = 0o755
+	// PLUGIN_DOWNLOAD_TIMEOUT bounds the download of a plugin, including its body
+	PLUGIN_DOWNLOAD_TIMEOUT = 5 * time.Minute
+	PLUGIN_ANNOTATION       = "plugin" // annotation holding the path of a plugin command
+)
+
+// commands added by cobra itself during execution, which plugins may not shadow
+var reservedCommands = []string{"help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+// plugin is an executable found in the plugins directory or on PATH
+type plugin struct {
+	name string
+	path string
+	// shadowedBy is set if the plugin can't be used, because a built-in command
+	// or another plugin earlier in the search order has the same name
+	shadowedBy string
+}
+
+// Parent plugin command
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage plugins, external executables that add commands to the CLI",
+	Long: `Manage plugins, external executables that add commands to the CLI.
+
+Any executable named cedana-cli-<name> in the plugins directory (~/.cedana/plugins)
+or on PATH is available as 'cedana-cli <name>'. The plugins directory is searched
+first, then PATH in order. Plugins can not replace built-in commands.
+
+All arguments after the plugin name are passed to the plugin as is. Plugins also
+receive the following environment variables:
+
+  CEDANA_URL              the resolved Cedana endpoint URL
+  CEDANA_AUTH_TOKEN       the resolved auth token
+  CEDANA_CLI_CONFIG_DIR   the config directory in use
+  CEDANA_CLI_LOG_LEVEL    the log level, as set by --verbose and --quiet
+  CEDANA_CLI_COLOR        when to color output (auto, always or never)
+  CEDANA_CLI_EXECUTABLE   the path of the cedana-cli executable
+
+NO_COLOR or CLICOLOR_FORCE are also set when colors are turned off or forced.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all plugins found in the plugins directory and on PATH",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins := findPlugins()
+		if len(plugins) == 0 {
+			fmt.Println("No plugins found")
+			return nil
+		}
+
+		tableWriter := table.NewWriter()
+		tableWriter.SetStyle(style.TableStyle)
+		tableWriter.SetOutputMirror(os.Stdout)
+		tableWriter.Style().Options.SeparateRows = false
+
+		tableWriter.AppendHeader(table.Row{
+			"Name",
+			"Path",
+			"Status",
+		})
+
+		for _, p := range plugins {
+			status := style.PositiveColors.Sprint("ok")
+			if p.shadowedBy != "" {
+				status = style.WarningColors.Sprint("shadowed by " + p.shadowedBy)
+			}
+			tableWriter.AppendRow(table.Row{
+				p.name,
+				p.path,
+				status,
+			})
+		}
+
+		tableWriter.Render()
+		return nil
+	},
+}
+
+var pluginInstallCmd = &cobra.Command{
+	Use:   "install <file|url>",
+	Short: "Install a plugin into the plugins directory",
+	Long: `Install a plugin into the plugins directory, from a local file or an HTTPS URL.
+
+The plugin is named after the file, without its cedana-cli- prefix, unless
+a name is given with --name. With --sha256, the plugin is only installed if its
+SHA-256 checksum matches, which is recommended when downloading it.`,
+	Example: `  cedana-cli plugin install ./cedana-cli-campaign
+  cedana-cli plugin install https://example.com/s3-stage --name s3-stage \
+    --sha256 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := args[0]
+		name, _ := cmd.Flags().GetString(flags.NameFlag.Full)
+		force, _ := cmd.Flags().GetBool(flags.ForceFlag.Full)
+		checksum, _ := cmd.Flags().GetString(flags.Sha256Flag.Full)
+
+		if strings.HasPrefix(source, "http://") {
+			return fmt.Errorf("refusing to download plugin over plain HTTP, use an HTTPS URL")
+		}
+		if checksum != "" {
+			if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+				return fmt.Errorf("invalid --%s %q, must be %d hex characters", flags.Sha256Flag.Full, checksum, 2*sha256.Size)
+			}
+		}
+
+		if name == "" {
+			name = pluginName(filepath.Base(source))
+		}
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, "-") {
+			return fmt.Errorf("invalid plugin name %q, give one with --%s", name, flags.NameFlag.Full)
+		}
+		if isBuiltinCommand(name) {
+			return fmt.Errorf("cannot install plugin %s, it would be shadowed by the built-in command", name)
+		}
+
+		dir, err := pluginDir()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, pluginFileName(name))
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("plugin %s is already installed, use --%s to replace it", name, flags.ForceFlag.Full)
+		}
+
+		var reader io.ReadCloser
+		if strings.HasPrefix(source, "https://") {
+			if checksum == "" {
+				fmt.Fprintln(os.Stderr, style.WarningColors.Sprintf("Warning: installing plugin without verifying it, pass --%s to check its checksum", flags.Sha256Flag.Full))
+			}
+			reader, err = downloadPlugin(cmd, source)
+		} else {
+			reader, err = os.Open(source)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read plugin: %w", err)
+		}
+		defer reader.Close()
+
+		if err := writePlugin(dir, path, reader, checksum); err != nil {
+			return fmt.Errorf("failed to install plugin: %w", err)
+		}
+
+		fmt.Printf("Installed plugin %s to %s\n", name, path)
+		return nil
+	},
+}
+
+var pluginRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a plugin from the plugins directory",
+	Args:  cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		dir, err := pluginDir()
+		if err != nil {
+			return completionError(err)
+		}
+		var names []string
+		for _, p := range pluginsIn(dir) {
+			names = append(names, p.name)
+		}
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		dir, err := pluginDir()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, pluginFileName(name))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			for _, p := range findPlugins() {
+				if p.name == name {
+					return fmt.Errorf("plugin %s is not installed in %s, remove %s manually", name, dir, p.path)
+				}
+			}
+			return fmt.Errorf("plugin %s is not installed", name)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove plugin: %w", err)
+		}
+
+		fmt.Printf("Removed plugin %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginCmd.AddCommand(pluginRemoveCmd)
+
+	pluginInstallCmd.Flags().
+		String(flags.NameFlag.Full, "", "name to install the plugin as (default from the file name)")
+	pluginInstallCmd.Flags().
+		Bool(flags.ForceFlag.Full, false, "replace the plugin if already installed")
+	pluginInstallCmd.Flags().
+		String(flags.Sha256Flag.Full, "", "expected SHA-256 checksum of the plugin, in hex")
+}
+
+// registerPlugins adds a command for every usable plugin. Must be called
+// after all built-in commands have been added.
+func registerPlugins(root *cobra.Command) {
+	for _, p := range findPlugins() {
+		if p.shadowedBy != "" {
+			log.Debug().Str("plugin", p.path).Str("shadowed_by", p.shadowedBy).Msg("ignoring shadowed plugin")
+			continue
+		}
+		root.AddCommand(&cobra.Command{
+			Use:         p.name,
+			Short:       "Plugin at " + p.path,
+			Annotations: map[string]string{PLUGIN_ANNOTATION: p.path},
+			Args:        cobra.ArbitraryArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPlugin(cmd, p, args)
+			},
+		})
+	}
+}
+
+// pluginArgs passes everything after the name of a plugin to the plugin, by
+// inserting a "--" after it. Global flags before the name are still parsed.
+func pluginArgs(root *cobra.Command, args []string) []string {
+	i := commandIndex(root, args)
+	if i < 0 {
+		return args
+	}
+	cmd, _, err := root.Find([]string{args[i]})
+	if err != nil || cmd == root || cmd.Annotations[PLUGIN_ANNOTATION] == "" {
+		return args
+	}
+	rewritten := append([]string{}, args[:i+1]...)
+	rewritten = append(rewritten, "--")
+	return append(rewritten, args[i+1:]...)
+}
+
+func runPlugin(cmd *cobra.Command, p plugin, args []string) error {
+	log.Debug().Str("plugin", p.path).Strs("args", args).Msg("running plugin")
+
+	plugin := exec.Command(p.path, args...)
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+	plugin.Env = append(os.Environ(), pluginEnv()...)
+
+	err := plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// the plugin has reported the error itself, only pass on its exit code
+		cmd.SilenceErrors = true
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", p.name, err)
+	}
+	return nil
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// findPlugins returns all plugins in search order, marking those that can't be
+// used because of an earlier command with the same name
+func findPlugins() []plugin {
+	var dirs []string
+	if dir, err := pluginDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+
+	var plugins []plugin
+	found := make(map[string]string)
+	seenDirs := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+		for _, p := range pluginsIn(dir) {
+			if isBuiltinCommand(p.name) {
+				p.shadowedBy = "built-in command"
+			} else if path, ok := found[p.name]; ok {
+				p.shadowedBy = path
+			} else {
+				found[p.name] = p.path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// pluginsIn returns the plugins in dir, sorted by name
+func pluginsIn(dir string) []plugin {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var plugins []plugin
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), PLUGIN_PREFIX) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path) // follow symlinks
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		if name := pluginName(entry.Name()); name != "" {
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].name < plugins[j].name })
+	return plugins
+}
+
+// pluginDir returns the plugins directory, always under the default config
+// directory as plugins are found before flags are parsed
+func pluginDir() (string, error) {
+	dir, err := config.DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.PLUGIN_DIR_NAME), nil
+}
+
+// pluginName returns the name of the command for a plugin file
+func pluginName(fileName string) string {
+	if runtime.GOOS == "windows" {
+		fileName = strings.TrimSuffix(fileName, ".exe")
+	}
+	return strings.TrimPrefix(fileName, PLUGIN_PREFIX)
+}
+
+func pluginFileName(name string) string {
+	if runtime.GOOS == "windows" {
+		return PLUGIN_PREFIX + name + ".exe"
+	}
+	return PLUGIN_PREFIX + name
+}
+
+func isBuiltinCommand(name string) bool {
+	for _, reserved := range reservedCommands {
+		if name == reserved {
+			return true
+		}
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Annotations[PLUGIN_ANNOTATION] == "" && (cmd.Name() == name || cmd.HasAlias(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func flagTakesValue(root *cobra.Command, arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	flag := root.PersistentFlags().Lookup(strings.TrimPrefix(arg, "--"))
+	if !strings.HasPrefix(arg, "--") && len(arg) == 2 {
+		flag = root.PersistentFlags().ShorthandLookup(arg[1:])
+	}
+	return flag != nil && flag.NoOptDefVal == ""
+}
+
+// pluginEnv returns the environment variables passed to plugins
+func pluginEnv() []string {
+	env := []string{
+		"CEDANA_URL=" + config.Global.Connection.URL,
+		"CEDANA_AUTH_TOKEN=" + config.Global.Connection.AuthToken,
+		"CEDANA_CLI_CONFIG_DIR=" + config.Dir,
+		"CEDANA_CLI_LOG_LEVEL=" + logging.Level(),
+		"CEDANA_CLI_COLOR=" + config.Global.Color,
+	}
+	switch config.Global.Color {
+	case style.COLOR_NEVER:
+		env = append(env, "NO_COLOR=1")
+	case style.COLOR_ALWAYS:
+		env = append(env, "CLICOLOR_FORCE=1")
+	}
+	if executable, err := os.Executable(); err == nil {
+		env = append(env, "CEDANA_CLI_EXECUTABLE="+executable)
+	}
+	return env
+}
+
+func downloadPlugin(cmd *cobra.Command, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := (&http.Client{Timeout: PLUGIN_DOWNLOAD_TIMEOUT}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// writePlugin writes the plugin to path atomically, so a failed install
+// never leaves a partial executable behind. If checksum is set, the plugin is
+// only written if its SHA-256 checksum matches.
+func writePlugin(dir, path string, reader io.Reader, checksum string) error {
+	if err := os.MkdirAll(dir, config.DIR_PERM); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".install-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), reader); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); checksum != "" && !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", strings.ToLower(checksum), sum)
+	}
+	if err := os.Chmod(tmp.Name(), PLUGIN_FILE_PERM); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
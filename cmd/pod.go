@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/spf13/cobra"
+)
+
+// Parent checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Checkpoint a resource",
+}
+
+var checkpointPodCmd = &cobra.Command{
+	Use:               "pod <name>",
+	Short:             "Checkpoint a running pod, leaving it running",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePods,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+
+		resp, err := client.CheckpointPod(cmd.Context(), clusterName, namespace, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		return nil
+	},
+}
+
+// Parent migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate a resource to another node",
+}
+
+var migratePodCmd = &cobra.Command{
+	Use:   "pod <name>",
+	Short: "Checkpoint a pod and restore it on another node",
+	Long: `Checkpoint a running pod and restore it on another node of the cluster.
+
+The node is picked by the platform, unless given with --target-node.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePods,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		targetNode, _ := cmd.Flags().GetString(flags.TargetNodeFlag.Full)
+
+		resp, err := client.MigratePod(cmd.Context(), clusterName, namespace, args[0], targetNode)
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		return nil
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:               "logs <pod>",
+	Short:             "Print the logs of a pod",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePods,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		tail, _ := cmd.Flags().GetInt(flags.TailFlag.Full)
+		if tail < 0 {
+			return fmt.Errorf("--%s must not be negative", flags.TailFlag.Full)
+		}
+
+		logs, err := client.GetPodLogs(cmd.Context(), clusterName, namespace, args[0], tail)
+		if err != nil {
+			return err
+		}
+		fmt.Print(logs)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkpointCmd)
+	checkpointCmd.AddCommand(checkpointPodCmd)
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migratePodCmd)
+	rootCmd.AddCommand(logsCmd)
+
+	for _, cmd := range []*cobra.Command{checkpointPodCmd, migratePodCmd, logsCmd} {
+		cmd.Flags().
+			StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+		cmd.MarkFlagRequired(flags.ClusterFlag.Full)
+		cmd.Flags().
+			StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+	}
+
+	migratePodCmd.Flags().
+		String(flags.TargetNodeFlag.Full, "", "node to restore the pod on (default picked by the platform)")
+	migratePodCmd.RegisterFlagCompletionFunc(flags.TargetNodeFlag.Full, completeNodes)
+	logsCmd.Flags().
+		Int(flags.TailFlag.Full, 0, "number of lines to print from the end of the logs, 0 for all")
+}
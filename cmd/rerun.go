@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/workload"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Parent rerun command
+var rerunCmd = &cobra.Command{
+	Use:   "rerun",
+	Short: "Submit a copy of an existing resource",
+}
+
+var rerunWorkloadCmd = &cobra.Command{
+	Use:   "workload <name>",
+	Short: "Submit a copy of a finished or failed workload",
+	Long: `Submit a copy of an existing workload, e.g. to re-run a failed simulation.
+
+The stored spec of the workload is fetched from the server, or taken from its
+latest revision if the server does not serve specs, and stripped of its status
+and the fields set by kubernetes. Overrides given with --set are applied to the
+workload, using dot-separated paths with [N] to index lists.
+
+The copy is named after --name, or the original name with a unique suffix. With
+--from-checkpoint, its pods are restored from the last checkpoint of the
+original workload instead of starting fresh.`,
+	Example: `  cedana-cli rerun workload gromacs -c prod-eks -n cedana
+  cedana-cli rerun workload gromacs -c prod-eks -n cedana --name gromacs-2 --from-checkpoint
+  cedana-cli rerun workload gromacs -c prod-eks -n cedana \
+    --set spec.parallelism=4 --set spec.template.spec.containers[0].image=gromacs:2024`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		name, _ := cmd.Flags().GetString(flags.NameFlag.Full)
+		overrides, _ := cmd.Flags().GetStringArray(flags.SetFlag.Full)
+		fromCheckpoint, _ := cmd.Flags().GetBool(flags.FromCheckpointFlag.Full)
+		dryRun, _ := cmd.Flags().GetBool(flags.DryRunFlag.Full)
+
+		job, err := client.GetWorkloadSpec(cmd.Context(), clusterName, namespace, args[0])
+		if err != nil {
+			return err
+		}
+		workload.StripRuntimeFields(job)
+
+		if name == "" {
+			name = args[0] + "-rerun-" + strconv.FormatInt(time.Now().Unix(), 36)
+		}
+		overrides = append([]string{
+			"metadata.name=" + strconv.Quote(name),
+			"metadata.namespace=" + strconv.Quote(namespace),
+		}, overrides...)
+		for _, override := range overrides {
+			if err := workload.SetOverride(job, override); err != nil {
+				return err
+			}
+		}
+		if fromCheckpoint {
+			workload.SetAnnotation(job, workload.RESTORE_FROM_ANNOTATION, namespace+"/"+args[0])
+		}
+
+		manifest := map[string]any{
+			"cluster_name": clusterName,
+			"workload":     job,
+		}
+		if dryRun {
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			return encoder.Encode(manifest)
+		}
+
+		payload, err := json.Marshal(manifest)
+		if err != nil {
+			return fmt.Errorf("failed to encode workload: %w", err)
+		}
+		resp, err := client.CreateWorkload(cmd.Context(), payload, string(client.CONTENT_TYPE_JSON))
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		fmt.Printf("Submitted workload %s as a copy of %s\n", name, args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rerunCmd)
+	rerunCmd.AddCommand(rerunWorkloadCmd)
+
+	rerunWorkloadCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	rerunWorkloadCmd.MarkFlagRequired(flags.ClusterFlag.Full)
+	rerunWorkloadCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+	rerunWorkloadCmd.Flags().String(flags.NameFlag.Full, "", "name of the copy (default the original name with a unique suffix)")
+	rerunWorkloadCmd.Flags().StringArray(flags.SetFlag.Full, nil, "override a field of the workload, as path=value (can be repeated)")
+	rerunWorkloadCmd.Flags().Bool(flags.FromCheckpointFlag.Full, false, "restore from the last checkpoint of the original workload instead of starting fresh")
+	rerunWorkloadCmd.Flags().Bool(flags.DryRunFlag.Full, false, "print the workload that would be submitted, without submitting it")
+}
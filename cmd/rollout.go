@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/revision"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Parent rollout command
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the revisions of a resource",
+	Long: `Manage the revisions of a resource.
+
+Each time a workload is created, its payload is kept as a new revision. The
+server keeps revisions where it supports them. Otherwise, the last 10 revisions
+of each workload created from this machine are stored under the config directory.`,
+}
+
+// Parent rollout history command
+var rolloutHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the revisions of a resource",
+}
+
+var rolloutHistoryWorkloadCmd = &cobra.Command{
+	Use:   "workload <name>",
+	Short: "Show the revisions of a workload, with the changes between them",
+	Long: `Show the revisions of a workload, with the number of lines changed from the
+previous revision. With --revision, show the diff between that revision and the
+previous one instead.`,
+	Example: `  cedana-cli rollout history workload gromacs -c prod-eks -n cedana
+  cedana-cli rollout history workload gromacs -c prod-eks -n cedana --revision 3`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		number, _ := cmd.Flags().GetInt(flags.RevisionFlag.Full)
+
+		revisions, local, err := client.GetWorkloadRevisions(cmd.Context(), clusterName, namespace, args[0])
+		if err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			if local {
+				return fmt.Errorf("no revisions of workload %s, the server does not keep them and it was not created from this machine", args[0])
+			}
+			return fmt.Errorf("no revisions of workload %s", args[0])
+		}
+
+		if number != 0 {
+			rev, ok := revision.Find(revisions, number)
+			if !ok {
+				return fmt.Errorf("revision %d of workload %s not found", number, args[0])
+			}
+			var previous []string
+			if prev, ok := revision.Find(revisions, number-1); ok {
+				previous = revisionLines(prev)
+			}
+			fmt.Printf("Revision %d, created %s\n\n", rev.Revision, rev.CreatedAt.Local().Format(time.DateTime))
+			for _, line := range diffLines(previous, revisionLines(rev)) {
+				fmt.Println(line)
+			}
+			return nil
+		}
+
+		tableWriter := table.NewWriter()
+		tableWriter.SetStyle(style.TableStyle)
+		tableWriter.SetOutputMirror(os.Stdout)
+		tableWriter.Style().Options.SeparateRows = false
+
+		tableWriter.AppendHeader(table.Row{
+			"Revision",
+			"Created",
+			"Changes",
+		})
+		var previous []string
+		for i := range revisions {
+			lines := revisionLines(&revisions[i])
+			added, removed := 0, 0
+			for _, line := range diffLines(previous, lines) {
+				switch line.op {
+				case '+':
+					added++
+				case '-':
+					removed++
+				}
+			}
+			changes := style.PositiveColors.Sprintf("+%d", added) + " " + style.NegativeColors.Sprintf("-%d", removed)
+			if i == 0 && revisions[i].Revision == 1 {
+				changes = "created"
+			}
+			tableWriter.AppendRow(table.Row{
+				revisions[i].Revision,
+				revisions[i].CreatedAt.Local().Format(time.DateTime),
+				changes,
+			})
+			previous = lines
+		}
+		tableWriter.Render()
+		if local {
+			fmt.Println("\nRevisions are stored locally, the server does not keep them")
+		}
+		return nil
+	},
+}
+
+// Parent rollout undo command
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Roll a resource back to a previous revision",
+}
+
+var rolloutUndoWorkloadCmd = &cobra.Command{
+	Use:   "workload <name>",
+	Short: "Roll a workload back to a previous revision",
+	Long: `Roll a workload back to the revision before the latest, or to the one given
+with --to-revision.
+
+If the server does not keep revisions, the workload is deleted and, once the
+deletion has completed, created again from the revision stored locally, which is
+recorded as a new revision.`,
+	Example: `  cedana-cli rollout undo workload gromacs -c prod-eks -n cedana
+  cedana-cli rollout undo workload gromacs -c prod-eks -n cedana --to-revision 2`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		to, _ := cmd.Flags().GetInt(flags.ToRevisionFlag.Full)
+
+		revisions, local, err := client.GetWorkloadRevisions(cmd.Context(), clusterName, namespace, args[0])
+		if err != nil {
+			return err
+		}
+		if to == 0 {
+			if len(revisions) < 2 {
+				return fmt.Errorf("workload %s has no previous revision to roll back to", args[0])
+			}
+			to = revisions[len(revisions)-2].Revision
+		}
+		if _, ok := revision.Find(revisions, to); !ok {
+			return fmt.Errorf("revision %d of workload %s not found", to, args[0])
+		}
+
+		resp, err := client.RollbackWorkload(cmd.Context(), clusterName, namespace, args[0], to, local)
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		fmt.Printf("Rolled workload %s back to revision %d\n", args[0], to)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rolloutCmd)
+	rolloutCmd.AddCommand(rolloutHistoryCmd)
+	rolloutHistoryCmd.AddCommand(rolloutHistoryWorkloadCmd)
+	rolloutCmd.AddCommand(rolloutUndoCmd)
+	rolloutUndoCmd.AddCommand(rolloutUndoWorkloadCmd)
+
+	for _, cmd := range []*cobra.Command{rolloutHistoryWorkloadCmd, rolloutUndoWorkloadCmd} {
+		cmd.Flags().
+			StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+		cmd.MarkFlagRequired(flags.ClusterFlag.Full)
+		cmd.Flags().
+			StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+	}
+	rolloutHistoryWorkloadCmd.Flags().Int(flags.RevisionFlag.Full, 0, "show the diff of this revision against the previous one")
+	rolloutUndoWorkloadCmd.Flags().Int(flags.ToRevisionFlag.Full, 0, "revision to roll back to (default the one before the latest)")
+}
+
+// revisionLines returns the payload of a revision as YAML lines, so revisions
+// submitted in different formats can be compared
+func revisionLines(rev *revision.Revision) []string {
+	ct, err := client.ParseContentType(rev.ContentType)
+	if err != nil || ct == client.CONTENT_TYPE_PROTOBUF {
+		return []string{fmt.Sprintf("<%s payload, %d bytes>", rev.ContentType, len(rev.Payload))}
+	}
+	var generic any
+	if ct == client.CONTENT_TYPE_JSON {
+		err = json.Unmarshal(rev.Payload, &generic)
+	} else {
+		err = yaml.Unmarshal(rev.Payload, &generic)
+	}
+	if err != nil {
+		return strings.Split(strings.TrimRight(string(rev.Payload), "\n"), "\n")
+	}
+	data, err := yaml.Marshal(generic)
+	if err != nil {
+		return strings.Split(strings.TrimRight(string(rev.Payload), "\n"), "\n")
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// diffLine is a line of a diff, with its operation: '+' if added, '-' if
+// removed, ' ' if kept
+type diffLine struct {
+	op   byte
+	text string
+}
+
+func (l diffLine) String() string {
+	line := string(l.op) + " " + l.text
+	switch l.op {
+	case '+':
+		return style.PositiveColors.Sprint(line)
+	case '-':
+		return style.NegativeColors.Sprint(line)
+	default:
+		return line
+	}
+}
+
+// diffLines returns the diff from lines a to lines b, based on their longest
+// common subsequence
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, diffLine{'-', a[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return diff
+}
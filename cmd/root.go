@@ -2,11 +2,18 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
 
+	"github.com/cedana/cedana-cli/client"
 	"github.com/cedana/cedana-cli/pkg/config"
 	"github.com/cedana/cedana-cli/pkg/flags"
 	"github.com/cedana/cedana-cli/pkg/logging"
+	"github.com/cedana/cedana-cli/pkg/style"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +30,26 @@ func init() {
 	rootCmd.PersistentFlags().String(flags.ConfigDirFlag.Full, "", "custom config directory")
 	rootCmd.MarkPersistentFlagDirname(flags.ConfigDirFlag.Full)
 	rootCmd.MarkFlagsMutuallyExclusive(flags.ConfigFlag.Full, flags.ConfigDirFlag.Full)
+	rootCmd.PersistentFlags().
+		BoolP(flags.VerboseFlag.Full, flags.VerboseFlag.Short, false, "enable debug logging for this invocation")
+	rootCmd.PersistentFlags().
+		BoolP(flags.QuietFlag.Full, flags.QuietFlag.Short, false, "only log errors for this invocation")
+	rootCmd.MarkFlagsMutuallyExclusive(flags.VerboseFlag.Full, flags.QuietFlag.Full)
+	rootCmd.PersistentFlags().
+		Bool(flags.TraceHTTPFlag.Full, false, "log all HTTP requests and responses (implies debug logging)")
+	rootCmd.PersistentFlags().
+		String(flags.HARFileFlag.Full, "", "dump all HTTP exchanges to a HAR file")
+	rootCmd.MarkPersistentFlagFilename(flags.HARFileFlag.Full, "har")
+	rootCmd.PersistentFlags().
+		Bool(flags.StrictDecodeFlag.Full, false, "fail on response fields unknown to this CLI, to catch API changes")
+	rootCmd.PersistentFlags().
+		Bool(flags.NoCacheFlag.Full, false, "do not use or update the local cache of listings")
+	rootCmd.PersistentFlags().
+		Bool(flags.NoColorFlag.Full, false, "disable colors and unicode marks in output")
+	rootCmd.PersistentFlags().
+		Bool(flags.StrictVersionFlag.Full, false, "fail if the server no longer supports this CLI version, instead of warning")
+	rootCmd.PersistentFlags().
+		Duration(flags.RequestTimeoutFlag.Full, 0, "maximum duration of a single request, 0 for no timeout (default from config)")
 }
 
 var (
@@ -50,22 +77,134 @@ var (
 				Config:    conf,
 				ConfigDir: confDir,
 			}); err != nil {
-				return fmt.Errorf("Failed to initialize config: %w", err)
+				if cmd != doctorCmd {
+					return fmt.Errorf("Failed to initialize config: %w", err)
+				}
+				configErr = err // diagnosed by doctor
+			}
+
+			if traceHTTP, _ := cmd.Flags().GetBool(flags.TraceHTTPFlag.Full); traceHTTP {
+				config.Global.Trace.HTTP = true
+			}
+			if harFile, _ := cmd.Flags().GetString(flags.HARFileFlag.Full); harFile != "" {
+				config.Global.Trace.HARFile = harFile
+			}
+			if strictDecode, _ := cmd.Flags().GetBool(flags.StrictDecodeFlag.Full); strictDecode {
+				config.Global.Trace.StrictDecode = true
+			}
+			if noCache, _ := cmd.Flags().GetBool(flags.NoCacheFlag.Full); noCache {
+				config.Global.Cache.Enabled = false
 			}
 
-			logging.SetLevel(config.Global.LogLevel)
+			if strictVersion, _ := cmd.Flags().GetBool(flags.StrictVersionFlag.Full); strictVersion {
+				config.Global.Connection.StrictVersion = true
+			}
+			if cmd.Flags().Changed(flags.RequestTimeoutFlag.Full) {
+				config.Global.Connection.Timeout, _ = cmd.Flags().GetDuration(flags.RequestTimeoutFlag.Full)
+			}
+
+			if noColor, _ := cmd.Flags().GetBool(flags.NoColorFlag.Full); noColor {
+				config.Global.Color = style.COLOR_NEVER
+			}
+			if err := style.SetColor(config.Global.Color); err != nil {
+				return err
+			}
+
+			logLevel := config.Global.LogLevel
+			if config.Global.Trace.HTTP && logLevel != "trace" {
+				logLevel = "debug" // traces are logged at debug level
+			}
+			if verbose, _ := cmd.Flags().GetBool(flags.VerboseFlag.Full); verbose {
+				logLevel = "debug"
+			}
+			if quiet, _ := cmd.Flags().GetBool(flags.QuietFlag.Full); quiet {
+				logLevel = "error"
+			}
+			logging.SetLevel(logLevel)
+
+			if err := logging.SetOutput(config.Global.LogFormat, config.Global.LogFile); err != nil {
+				return fmt.Errorf("Failed to initialize logging: %w", err)
+			}
+
+			client.Command = auditCommand(cmd.Root().Name(), commandArgs)
+
+			log.Debug().Str("dir", config.Dir).Str("url", config.Global.Connection.URL).Msg("loaded config")
 
 			return nil
 		},
 	}
 )
 
+// commandArgs are the arguments of the invocation with any alias expanded, as
+// recorded in the audit log
+var commandArgs []string
+
 func Execute(ctx context.Context, version string) error {
 	ctx = log.With().Str("context", "cmd").Logger().WithContext(ctx)
 
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Cancel the command, and with it any in-flight requests, on the first
+	// interrupt. A second interrupt exits immediately.
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		sig := <-signals
+		cancel(&InterruptedError{Signal: sig})
+		sig = <-signals
+		os.Exit(ExitCode(&InterruptedError{Signal: sig}))
+	}()
+
 	rootCmd.Version = version
 	rootCmd.Long = rootCmd.Long + "\n " + version
 	rootCmd.SilenceUsage = true // only show usage when true usage error
+	registerPlugins(rootCmd)
+	registerCompletions(rootCmd)
+
+	args, err := expandAlias(rootCmd, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return err
+	}
+	commandArgs = args
+	rootCmd.SetArgs(pluginArgs(rootCmd, args))
+
+	err = rootCmd.ExecuteContext(ctx)
 
-	return rootCmd.ExecuteContext(ctx)
+	var interrupted *InterruptedError
+	if cause := context.Cause(ctx); errors.As(cause, &interrupted) {
+		return interrupted
+	}
+	return err
+}
+
+// InterruptedError is returned by Execute when the command was interrupted by a signal
+type InterruptedError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+// ExitCode returns the conventional exit status for an error returned by
+// Execute: 128+n if interrupted by signal n, the exit status of a plugin
+// that failed, 1 for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var interrupted *InterruptedError
+	if errors.As(err, &interrupted) {
+		if sig, ok := interrupted.Signal.(syscall.Signal); ok {
+			return 128 + int(sig)
+		}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/spf13/cobra"
+)
+
+// Parent set command
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set a property of an existing resource",
+}
+
+var setPriorityCmd = &cobra.Command{
+	Use:   "priority",
+	Short: "Set the scheduling priority of a resource",
+}
+
+var setPriorityWorkloadCmd = &cobra.Command{
+	Use:   "workload <name> <priority-class>",
+	Short: "Set the workload priority class of a workload",
+	Long: `Set the kueue workload priority class of a workload. Workloads with a higher
+priority are admitted ahead of others pending in the same cluster queue.`,
+	Example:           `  cedana-cli set priority workload gromacs-md-simulation urgent -c prod -n cedana`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+
+		resp, err := client.SetWorkloadPriority(cmd.Context(), clusterName, namespace, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setCmd)
+	setCmd.AddCommand(setPriorityCmd)
+	setPriorityCmd.AddCommand(setPriorityWorkloadCmd)
+
+	setPriorityWorkloadCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	setPriorityWorkloadCmd.MarkFlagRequired(flags.ClusterFlag.Full)
+	setPriorityWorkloadCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+}
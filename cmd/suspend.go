@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/spf13/cobra"
+)
+
+// Parent suspend command
+var suspendCmd = &cobra.Command{
+	Use:   "suspend",
+	Short: "Suspend a resource",
+}
+
+var suspendWorkloadCmd = &cobra.Command{
+	Use:   "workload <name>",
+	Short: "Suspend a queued or running workload",
+	Long: `Suspend a queued or running workload, releasing its queue quota and nodes.
+
+With --checkpoint, the workload's pods are checkpointed before being stopped,
+so that resuming the workload continues from the saved state instead of
+starting over.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		checkpoint, _ := cmd.Flags().GetBool(flags.CheckpointFlag.Full)
+
+		resp, err := client.SuspendWorkload(cmd.Context(), clusterName, namespace, args[0], checkpoint)
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		return nil
+	},
+}
+
+// Parent resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume a suspended resource",
+}
+
+var resumeWorkloadCmd = &cobra.Command{
+	Use:   "workload <name>",
+	Short: "Resume a suspended workload",
+	Long: `Resume a suspended workload, queueing it for admission again.
+
+If a checkpoint was taken when the workload was suspended, it is restored from
+that checkpoint unless --fresh is set.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkloads,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		fresh, _ := cmd.Flags().GetBool(flags.FreshFlag.Full)
+
+		resp, err := client.ResumeWorkload(cmd.Context(), clusterName, namespace, args[0], !fresh)
+		if err != nil {
+			return err
+		}
+		fmt.Println(resp)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suspendCmd)
+	suspendCmd.AddCommand(suspendWorkloadCmd)
+	rootCmd.AddCommand(resumeCmd)
+	resumeCmd.AddCommand(resumeWorkloadCmd)
+
+	for _, cmd := range []*cobra.Command{suspendWorkloadCmd, resumeWorkloadCmd} {
+		cmd.Flags().
+			StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+		cmd.MarkFlagRequired(flags.ClusterFlag.Full)
+		cmd.Flags().
+			StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace")
+	}
+
+	suspendWorkloadCmd.Flags().
+		Bool(flags.CheckpointFlag.Full, false, "checkpoint the workload before suspending it")
+	resumeWorkloadCmd.Flags().
+		Bool(flags.FreshFlag.Full, false, "start fresh instead of restoring from the suspend checkpoint")
+}
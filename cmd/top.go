@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+const (
+	DEFAULT_TOP_INTERVAL  = 5 * time.Second
+	DEFAULT_TOP_THRESHOLD = 90.0 // percent of limit
+)
+
+// Parent top command
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Display resource usage of nodes, pods or workloads",
+	Long: `Display CPU, memory and (where reported) GPU utilisation of nodes, pods or workloads
+alongside their requests and limits. Rows using more than the threshold percentage
+of their limit are highlighted, and rows exceeding their limit are marked in red.`,
+}
+
+var topNodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Display resource usage of nodes under given cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, err := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster flag: %w", err)
+		}
+
+		return runTop(cmd, false, func() ([]client.ResourceUsage, error) {
+			return client.GetNodeUsage(cmd.Context(), clusterName)
+		})
+	},
+}
+
+var topPodCmd = &cobra.Command{
+	Use:   "pod",
+	Short: "Display resource usage of pods under given namespace of a cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, err := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster flag: %w", err)
+		}
+		clusterNamespace, err := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get namespace flag: %w", err)
+		}
+
+		return runTop(cmd, true, func() ([]client.ResourceUsage, error) {
+			return client.GetPodUsage(cmd.Context(), clusterName, clusterNamespace)
+		})
+	},
+}
+
+var topWorkloadCmd = &cobra.Command{
+	Use:   "workload",
+	Short: "Display resource usage of workloads under given namespace of a cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, err := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster flag: %w", err)
+		}
+		clusterNamespace, err := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		if err != nil {
+			return fmt.Errorf("failed to get namespace flag: %w", err)
+		}
+
+		return runTop(cmd, true, func() ([]client.ResourceUsage, error) {
+			return client.GetWorkloadUsage(cmd.Context(), clusterName, clusterNamespace)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.AddCommand(topNodeCmd)
+	topCmd.AddCommand(topPodCmd)
+	topCmd.AddCommand(topWorkloadCmd)
+
+	topCmd.PersistentFlags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster name")
+	topCmd.MarkPersistentFlagRequired(flags.ClusterFlag.Full)
+	topCmd.PersistentFlags().
+		String(flags.SortByFlag.Full, "name", "column to sort by ("+strings.Join(topSortColumns(), ", ")+")")
+	topCmd.PersistentFlags().
+		BoolP(flags.WatchFlag.Full, flags.WatchFlag.Short, false, "keep refreshing the usage")
+	topCmd.PersistentFlags().
+		Duration(flags.IntervalFlag.Full, DEFAULT_TOP_INTERVAL, "refresh interval when watching")
+	topCmd.PersistentFlags().
+		Float64(flags.ThresholdFlag.Full, DEFAULT_TOP_THRESHOLD, "percentage of limit above which a row is highlighted")
+
+	topPodCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "", "namespace")
+	topWorkloadCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "", "namespace")
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// topSorters maps sortable column names to a less function. Names sort
+// ascending, while resource columns sort descending so the heaviest users come first.
+var topSorters = map[string]func(a, b client.ResourceUsage) bool{
+	"name":           func(a, b client.ResourceUsage) bool { return a.Name < b.Name },
+	"namespace":      func(a, b client.ResourceUsage) bool { return a.Namespace < b.Namespace },
+	"cpu":            func(a, b client.ResourceUsage) bool { return a.CPUUsage > b.CPUUsage },
+	"cpu-request":    func(a, b client.ResourceUsage) bool { return a.CPURequest > b.CPURequest },
+	"cpu-limit":      func(a, b client.ResourceUsage) bool { return a.CPULimit > b.CPULimit },
+	"memory":         func(a, b client.ResourceUsage) bool { return a.MemoryUsage > b.MemoryUsage },
+	"memory-request": func(a, b client.ResourceUsage) bool { return a.MemoryRequest > b.MemoryRequest },
+	"memory-limit":   func(a, b client.ResourceUsage) bool { return a.MemoryLimit > b.MemoryLimit },
+	"gpu":            func(a, b client.ResourceUsage) bool { return deref(a.GPUUsage) > deref(b.GPUUsage) },
+	"gpu-request":    func(a, b client.ResourceUsage) bool { return deref(a.GPURequest) > deref(b.GPURequest) },
+	"gpu-limit":      func(a, b client.ResourceUsage) bool { return deref(a.GPULimit) > deref(b.GPULimit) },
+}
+
+func topSortColumns() []string {
+	columns := make([]string, 0, len(topSorters))
+	for column := range topSorters {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// runTop fetches and renders usage once, or repeatedly if watching.
+func runTop(cmd *cobra.Command, namespaced bool, fetch func() ([]client.ResourceUsage, error)) error {
+	sortBy, _ := cmd.Flags().GetString(flags.SortByFlag.Full)
+	watch, _ := cmd.Flags().GetBool(flags.WatchFlag.Full)
+	interval, _ := cmd.Flags().GetDuration(flags.IntervalFlag.Full)
+	threshold, _ := cmd.Flags().GetFloat64(flags.ThresholdFlag.Full)
+
+	less, ok := topSorters[sortBy]
+	if !ok {
+		return fmt.Errorf("invalid sort column %q, must be one of: %s", sortBy, strings.Join(topSortColumns(), ", "))
+	}
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	render := func() error {
+		usage, err := fetch()
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(usage, func(i, j int) bool { return less(usage[i], usage[j]) })
+		renderTop(usage, namespaced, threshold)
+		return nil
+	}
+
+	if !watch {
+		return render()
+	}
+
+	ctx := cmd.Context()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	clearScreen := style.IsTerminal(os.Stdout)
+
+	for {
+		if clearScreen {
+			fmt.Print("\033[H\033[2J") // clear screen
+		}
+		if err := render(); err != nil {
+			return err
+		}
+		fmt.Printf("\nEvery %s, last updated %s\n", interval, time.Now().Format(time.TimeOnly))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func renderTop(usage []client.ResourceUsage, namespaced bool, threshold float64) {
+	if len(usage) == 0 {
+		fmt.Println("No resource usage to show")
+		return
+	}
+
+	hasGPU := false
+	for _, u := range usage {
+		if u.GPUUsage != nil || u.GPURequest != nil || u.GPULimit != nil {
+			hasGPU = true
+			break
+		}
+	}
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetStyle(style.TableStyle)
+	tableWriter.SetOutputMirror(os.Stdout)
+	tableWriter.Style().Options.SeparateRows = false
+
+	header := table.Row{"Name"}
+	if namespaced {
+		header = append(header, "Namespace")
+	}
+	header = append(header, "CPU", "CPU Req", "CPU Lim", "CPU %", "Memory", "Mem Req", "Mem Lim", "Mem %")
+	if hasGPU {
+		header = append(header, "GPU", "GPU Req", "GPU Lim")
+	}
+	tableWriter.AppendHeader(header)
+
+	for _, u := range usage {
+		row := table.Row{u.Name}
+		if namespaced {
+			row = append(row, u.Namespace)
+		}
+		row = append(row,
+			formatCPU(u.CPUUsage),
+			formatCPU(u.CPURequest),
+			formatCPU(u.CPULimit),
+			formatPercent(u.CPUUsage, u.CPURequest),
+			formatBytes(u.MemoryUsage),
+			formatBytes(u.MemoryRequest),
+			formatBytes(u.MemoryLimit),
+			formatPercent(float64(u.MemoryUsage), float64(u.MemoryRequest)),
+		)
+		if hasGPU {
+			row = append(row, formatGPU(u.GPUUsage), formatGPU(u.GPURequest), formatGPU(u.GPULimit))
+		}
+
+		if colors := usageColors(u, threshold); colors != nil {
+			for i := range row {
+				row[i] = colors.Sprint(row[i])
+			}
+		}
+		tableWriter.AppendRow(row)
+	}
+
+	tableWriter.Render()
+}
+
+// usageColors returns the colors to paint a row with, based on the highest
+// utilisation of any resource relative to its limit. Returns nil if no
+// resource is above the threshold.
+func usageColors(u client.ResourceUsage, threshold float64) text.Colors {
+	ratios := []float64{
+		percent(u.CPUUsage, u.CPULimit),
+		percent(float64(u.MemoryUsage), float64(u.MemoryLimit)),
+		percent(deref(u.GPUUsage), deref(u.GPULimit)),
+	}
+	highest := 0.0
+	for _, r := range ratios {
+		highest = max(highest, r)
+	}
+
+	switch {
+	case highest >= 100:
+		return style.NegativeColors
+	case highest >= threshold:
+		return style.WarningColors
+	default:
+		return nil
+	}
+}
+
+func percent(value, of float64) float64 {
+	if of <= 0 {
+		return 0
+	}
+	return value / of * 100
+}
+
+func deref(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+func formatPercent(value, of float64) string {
+	if of <= 0 {
+		return style.DashMark
+	}
+	return fmt.Sprintf("%.0f%%", percent(value, of))
+}
+
+func formatCPU(cores float64) string {
+	if cores <= 0 {
+		return style.DashMark
+	}
+	if cores < 1 {
+		return fmt.Sprintf("%.0fm", cores*1000)
+	}
+	return fmt.Sprintf("%.2f", cores)
+}
+
+func formatGPU(gpus *float64) string {
+	if gpus == nil {
+		return style.DashMark
+	}
+	return fmt.Sprintf("%.1f", *gpus)
+}
+
+func formatBytes(bytes int64) string {
+	if bytes <= 0 {
+		return style.DashMark
+	}
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ci", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
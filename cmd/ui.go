@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/logging"
+	"github.com/cedana/cedana-cli/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	DEFAULT_UI_INTERVAL = 10 * time.Second
+
+	// UI_LOG_FILE receives the logs meant for stderr while the dashboard runs
+	UI_LOG_FILE = "ui.log"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and manage clusters in an interactive terminal dashboard",
+	Long: `Browse and manage clusters in an interactive terminal dashboard.
+
+Nodes, pods and workloads of the selected cluster are shown in navigable tables,
+with the events (or logs, for pods) of the selected row in a side pane. Pods can
+be checkpointed or migrated, and workloads suspended, resumed or deleted, each
+after confirmation. Key bindings are shown at the bottom of the screen.
+
+If no cluster is given, it is picked from a list on start.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName, _ := cmd.Flags().GetString(flags.ClusterFlag.Full)
+		namespace, _ := cmd.Flags().GetString(flags.NamespaceFlag.Full)
+		interval, _ := cmd.Flags().GetDuration(flags.IntervalFlag.Full)
+
+		// Anything written to the terminal would garble the dashboard, so logs
+		// are moved to a file while it runs
+		client.WarningOutput = io.Discard
+		if logging.Level() != "" && logging.ToStderr() {
+			if err := logging.SetOutput(config.Global.LogFormat, UI_LOG_FILE); err != nil {
+				return err
+			}
+			defer func() {
+				logging.SetOutput(config.Global.LogFormat, "")
+				fmt.Fprintf(os.Stderr, "Logs were written to %s\n", filepath.Join(config.Dir, config.LOG_DIR_NAME, UI_LOG_FILE))
+			}()
+		}
+
+		return ui.Run(cmd.Context(), ui.Options{
+			ClusterName: clusterName,
+			Namespace:   namespace,
+			Interval:    interval,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().
+		StringP(flags.ClusterFlag.Full, flags.ClusterFlag.Short, "", "cluster to open")
+	uiCmd.Flags().
+		StringP(flags.NamespaceFlag.Full, flags.NamespaceFlag.Short, "default", "namespace to open")
+	uiCmd.Flags().
+		Duration(flags.IntervalFlag.Full, DEFAULT_UI_INTERVAL, "refresh interval, 0 to only refresh on demand")
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade cedana-cli to the latest release",
+	Long: `Upgrade cedana-cli to the latest release, or to the version given with --version.
+
+Releases are looked up in the release index set in the config (upgrade.index_url),
+which defaults to the GitHub releases of cedana-cli. The archive for the current
+OS and architecture is downloaded, verified against the release's checksums, and
+the running binary is replaced atomically. Writing to the directory of the binary
+may require sudo.`,
+	Example: `  cedana-cli upgrade --check
+  cedana-cli upgrade
+  cedana-cli upgrade --version v0.4.2`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool(flags.CheckFlag.Full)
+		version, _ := cmd.Flags().GetString(flags.VersionFlag.Full)
+		force, _ := cmd.Flags().GetBool(flags.ForceFlag.Full)
+
+		release, err := upgrade.FindRelease(cmd.Context(), config.Global.Upgrade.IndexURL, version)
+		if err != nil {
+			return err
+		}
+
+		current := versionInfo.version
+		order, compareErr := client.CompareVersions(release.Version(), current)
+
+		if check {
+			switch {
+			case compareErr != nil:
+				fmt.Printf("The latest release is cedana-cli %s, which can't be compared with %s\n", release.Tag, current)
+			case order == 0:
+				fmt.Printf("cedana-cli %s is up to date\n", current)
+			case order > 0:
+				fmt.Printf("cedana-cli %s is available (current: %s), run `cedana-cli upgrade` to install it\n", release.Tag, current)
+			default:
+				fmt.Printf("cedana-cli %s is newer than %s\n", current, release.Tag)
+			}
+			return nil
+		}
+
+		if !force {
+			switch {
+			case compareErr != nil:
+				fmt.Printf("Cannot tell whether cedana-cli %s is newer than %s (%v), use --%s to install it anyway\n",
+					release.Tag, current, compareErr, flags.ForceFlag.Full)
+				return nil
+			case order < 0 && version == "":
+				fmt.Printf("cedana-cli %s is newer than the latest release %s, use --%s to downgrade\n", current, release.Tag, flags.ForceFlag.Full)
+				return nil
+			}
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the running binary: %w", err)
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return fmt.Errorf("failed to locate the running binary: %w", err)
+		}
+
+		if order != 0 || force {
+			fmt.Printf("Installing cedana-cli %s to %s...\n", release.Tag, executable)
+		}
+		installed, err := upgrade.Upgrade(cmd.Context(), release, current, executable, force)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade: %w", err)
+		}
+		if !installed {
+			fmt.Printf("cedana-cli %s is already installed\n", current)
+			return nil
+		}
+		fmt.Printf("Upgraded cedana-cli from %s to %s\n", current, release.Tag)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().Bool(flags.CheckFlag.Full, false, "only report whether a newer version is available")
+	upgradeCmd.Flags().String(flags.VersionFlag.Full, "", "install this version instead of the latest, e.g. v0.4.2")
+	upgradeCmd.Flags().Bool(flags.ForceFlag.Full, false, "reinstall the current version, downgrade to an older latest release, or install a release that can't be compared with the current version")
+}
@@ -2,9 +2,92 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/flags"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
 )
 
+// versionInfo is the build info of the CLI, set in SetVersionInfo
+var versionInfo struct {
+	version string
+	commit  string
+	date    string
+}
+
 // used in main.go to set version info
 func SetVersionInfo(version, commit, date string) {
 	rootCmd.Version = fmt.Sprintf("%s (%s)", version, commit)
+	versionInfo.version, versionInfo.commit, versionInfo.date = version, commit, date
+	client.SetVersion(version, commit)
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the version of the CLI, and optionally of the server",
+	Long: `Show the version of the CLI and the API version it speaks.
+
+With --server, also show the version of the server, the API versions it
+supports and the oldest CLI version it supports, and whether this CLI is
+compatible with it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, _ := cmd.Flags().GetBool(flags.ServerFlag.Full)
+
+		tableWriter := table.NewWriter()
+		tableWriter.SetStyle(style.TableStyle)
+		tableWriter.SetOutputMirror(os.Stdout)
+
+		tableWriter.AppendRow(table.Row{"CLI version", versionInfo.version})
+		tableWriter.AppendRow(table.Row{"Commit", versionInfo.commit})
+		tableWriter.AppendRow(table.Row{"Built", versionInfo.date})
+		tableWriter.AppendRow(table.Row{"API version", client.API_VERSION})
+
+		if !server {
+			tableWriter.Render()
+			return nil
+		}
+
+		client.WarningOutput = io.Discard // compatibility is shown below
+		serverVersion, err := client.GetServerVersion(cmd.Context())
+		if err != nil {
+			tableWriter.Render()
+			return err
+		}
+
+		supported := client.CLISupported(serverVersion.MinCLIVersion)
+		apiSupported := len(serverVersion.APIVersions) == 0 || slices.Contains(serverVersion.APIVersions, client.API_VERSION)
+
+		tableWriter.AppendSeparator()
+		tableWriter.AppendRow(table.Row{"Server version", valueOrDash(serverVersion.Version)})
+		tableWriter.AppendRow(table.Row{"Server API versions", valueOrDash(strings.Join(serverVersion.APIVersions, ", "))})
+		tableWriter.AppendRow(table.Row{"Minimum CLI version", valueOrDash(serverVersion.MinCLIVersion)})
+		tableWriter.AppendRow(table.Row{"Compatible", style.BoolStr(supported && apiSupported)})
+		tableWriter.Render()
+
+		if !supported {
+			fmt.Printf("\nThis CLI is older than the server supports, please upgrade cedana-cli to %s or newer\n", serverVersion.MinCLIVersion)
+		} else if !apiSupported {
+			fmt.Printf("\nThe server no longer speaks API version %s, please upgrade cedana-cli\n", client.API_VERSION)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().Bool(flags.ServerFlag.Full, false, "also show the version of the server and whether it supports this CLI")
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return style.DashMark
+	}
+	return s
 }
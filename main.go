@@ -4,6 +4,7 @@ package main
 // because it's not imported anywhere else.
 import (
 	"context"
+	"os"
 
 	"github.com/cedana/cedana-cli/cmd"
 )
@@ -17,5 +18,7 @@ var (
 
 func main() {
 	cmd.SetVersionInfo(version, commit, date)
-	cmd.Execute(context.Background(), version)
+	if err := cmd.Execute(context.Background(), version); err != nil {
+		os.Exit(cmd.ExitCode(err))
+	}
 }
@@ -0,0 +1,134 @@
+package audit
+
+// Append-only local audit log of mutating operations, one JSON entry per
+// line, so it can be inspected with standard tools as well as the history
+// command.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FILE_NAME = "audit.log"
+	FILE_PERM = 0o600
+)
+
+// Entry is a single mutating operation recorded in the audit log
+type Entry struct {
+	Time time.Time `json:"time"`
+	// User is the local user that ran the command, with the user that
+	// invoked sudo, if any, e.g. "root (sudo by alice)"
+	User string `json:"user"`
+	Host string `json:"host"`
+	// ConfigDir and URL identify the config and endpoint the operation was made with
+	ConfigDir string `json:"config_dir"`
+	URL       string `json:"url"`
+	// Command is the CLI command line, with secrets left out
+	Command string `json:"command"`
+	// Action is the operation, e.g. "delete workload"
+	Action string `json:"action"`
+	// Resource is the target of the operation, e.g. "prod-eks/cedana/train-42"
+	Resource string `json:"resource"`
+	// Result is "ok", or the error the operation failed with
+	Result string `json:"result"`
+	Status int    `json:"status,omitempty"`
+}
+
+// Filter selects entries of the audit log. Zero fields match everything.
+type Filter struct {
+	Since time.Time
+	// Resource matches entries whose resource contains it
+	Resource string
+}
+
+func (f Filter) match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Resource != "" && !strings.Contains(e.Resource, f.Resource) {
+		return false
+	}
+	return true
+}
+
+var mu sync.Mutex
+
+// Path returns the path of the audit log under a config directory
+func Path(dir string) string {
+	return filepath.Join(dir, FILE_NAME)
+}
+
+// CurrentUser returns the user to record in entries
+func CurrentUser() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != name {
+		name = fmt.Sprintf("%s (sudo by %s)", name, sudoUser)
+	}
+	return name
+}
+
+// Append adds an entry to the audit log under a config directory. The file is
+// only ever opened for appending, so existing entries are never rewritten.
+func Append(dir string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.OpenFile(Path(dir), os.O_WRONLY|os.O_APPEND|os.O_CREATE, FILE_PERM)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read returns the entries of the audit log under a config directory that
+// match filter, oldest first. A missing log has no entries.
+func Read(dir string, filter Filter) ([]Entry, error) {
+	file, err := os.Open(Path(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid entry: %v", Path(dir), line, err)
+		}
+		if filter.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
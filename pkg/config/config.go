@@ -1,11 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cedana/cedana/pkg/utils"
 	"github.com/spf13/viper"
@@ -16,25 +18,57 @@ const (
 	FILE_NAME  = "cli-config"
 	FILE_TYPE  = "json"
 	DIR_PERM   = 0o755
-	FILE_PERM  = 0o644
+	FILE_PERM  = 0o600 // the config file holds the auth token, keep it private
 	ENV_PREFIX = "CEDANA_CLI"
 
 	DEFAULT_SOCK_PERMS = 0o666
 
-	DEFAULT_LOG_LEVEL = "info"
+	DEFAULT_TIMEOUT = 1 * time.Minute
+
+	DEFAULT_LOG_LEVEL  = "info"
+	DEFAULT_LOG_FORMAT = "console"
+	LOG_DIR_NAME       = "logs"
+
+	DEFAULT_COLOR = "auto"
+
+	DEFAULT_CACHE_TTL = 5 * time.Minute
+	CACHE_DIR_NAME    = "cache"
+
+	PLUGIN_DIR_NAME = "plugins"
+
+	DEFAULT_UPGRADE_INDEX_URL = "https://api.github.com/repos/cedana/cedana-cli/releases"
 )
 
 // The default global config. This will get overwritten
 // by the config file or env vars during startup, if they exist.
 var Global Config = Config{
+	LogFormat: DEFAULT_LOG_FORMAT,
+	Color:     DEFAULT_COLOR,
 	// NOTE: Don't specify default address here as it depends on default protocol.
 	// Use above constants for default address for each protocol.
 	Connection: Connection{
 		URL:       "",
 		AuthToken: "",
+		Timeout:   DEFAULT_TIMEOUT,
+	},
+	Trace: Trace{
+		RedactFields: []string{"auth_token", "token", "password", "secret"},
+	},
+	Cache: Cache{
+		Enabled: true,
+		TTL:     DEFAULT_CACHE_TTL,
+	},
+	Audit: Audit{
+		Enabled: true,
+	},
+	Upgrade: Upgrade{
+		IndexURL: DEFAULT_UPGRADE_INDEX_URL,
 	},
 }
 
+// Dir is the config directory in use, set during Init
+var Dir string
+
 func init() {
 	setDefaults()
 	bindEnvVars()
@@ -46,6 +80,15 @@ type InitArgs struct {
 	ConfigDir string
 }
 
+// DefaultDir returns the config directory used when none is given
+func DefaultDir() (string, error) {
+	user, err := utils.GetUser()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(user.HomeDir, DIR_NAME), nil
+}
+
 func Init(args InitArgs) error {
 	user, err := utils.GetUser()
 	if err != nil {
@@ -54,12 +97,13 @@ func Init(args InitArgs) error {
 
 	var configDir string
 	if args.ConfigDir == "" {
-		homeDir := user.HomeDir
-		configDir = filepath.Join(homeDir, DIR_NAME)
+		configDir = filepath.Join(user.HomeDir, DIR_NAME)
 	} else {
 		configDir = args.ConfigDir
 	}
 
+	Dir = configDir
+
 	viper.AddConfigPath(configDir)
 	viper.SetConfigPermissions(FILE_PERM)
 	viper.SetConfigType(FILE_TYPE)
@@ -126,15 +170,121 @@ func setDefaults() {
 func bindEnvVars() {
 	for _, field := range utils.ListLeaves(Config{}) {
 		tag := utils.GetTag(Config{}, field, FILE_TYPE)
-		envVar := ENV_PREFIX + "_" + strings.ToUpper(strings.ReplaceAll(tag, ".", "_"))
-
-		// get env aliases from struct tag
-		aliasesStr := utils.GetTag(Config{}, field, "env_aliases")
-		aliases := []string{tag, envVar}
-		aliases = append(aliases, strings.Split(aliasesStr, ",")...)
+		aliases := append([]string{tag}, envVars(field)...)
 
 		viper.MustBindEnv(aliases...)
 	}
 
 	viper.AutomaticEnv()
 }
+
+// envVars returns the env vars bound to a config field: the prefixed
+// name of its key, followed by the aliases from its struct tag
+func envVars(field string) []string {
+	tag := utils.GetTag(Config{}, field, FILE_TYPE)
+	envVar := ENV_PREFIX + "_" + strings.ToUpper(strings.ReplaceAll(tag, ".", "_"))
+
+	// get env aliases from struct tag
+	aliasesStr := utils.GetTag(Config{}, field, "env_aliases")
+	vars := []string{envVar}
+	for _, alias := range strings.Split(aliasesStr, ",") {
+		if alias != "" {
+			vars = append(vars, alias)
+		}
+	}
+	return vars
+}
+
+// EnvOverrides returns the config keys set through env vars, with the env
+// var each one is read from
+func EnvOverrides() map[string]string {
+	overrides := make(map[string]string)
+	for _, field := range utils.ListLeaves(Config{}) {
+		for _, envVar := range envVars(field) {
+			if _, ok := os.LookupEnv(envVar); ok {
+				overrides[utils.GetTag(Config{}, field, FILE_TYPE)] = envVar
+				break
+			}
+		}
+	}
+	return overrides
+}
+
+// File returns the path of the config file in use
+func File() string {
+	return filepath.Join(Dir, FILE_NAME+"."+FILE_TYPE)
+}
+
+// SetAlias adds or replaces an alias, in the config file and the global config
+func SetAlias(name, command string) error {
+	err := updateFile(func(file map[string]any) {
+		aliases, _ := file["aliases"].(map[string]any)
+		if aliases == nil {
+			aliases = make(map[string]any)
+		}
+		aliases[name] = command
+		file["aliases"] = aliases
+	})
+	if err != nil {
+		return err
+	}
+	if Global.Aliases == nil {
+		Global.Aliases = make(map[string]string)
+	}
+	Global.Aliases[name] = command
+	return nil
+}
+
+// DeleteAlias removes an alias, from the config file and the global config
+func DeleteAlias(name string) error {
+	err := updateFile(func(file map[string]any) {
+		if aliases, ok := file["aliases"].(map[string]any); ok {
+			delete(aliases, name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	delete(Global.Aliases, name)
+	return nil
+}
+
+// updateFile applies update to the contents of the config file and writes
+// it back. Only the file is read, so values from env vars or a one-time
+// config string never end up in it.
+func updateFile(update func(file map[string]any)) error {
+	path := File()
+	file := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("Config file %s is invalid: %w", path, err)
+		}
+	}
+
+	update(file)
+
+	data, err = json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(Dir, FILE_NAME+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), FILE_PERM); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type (
 	// Cedana configuration. Each of the below fields can also be set
 	// through an environment variable with the same name, prefixed, and in uppercase. E.g.
@@ -8,8 +10,29 @@ type (
 	Config struct {
 		// LogLevel is the default log level used by the server
 		LogLevel string `json:"log_level" key:"log_level" yaml:"log_level" mapstructure:"log_level"`
+		// LogFormat is the format of log lines, either "console" or "json"
+		LogFormat string `json:"log_format" key:"log_format" yaml:"log_format" mapstructure:"log_format"`
+		// LogFile is the file to write logs to instead of stderr. Relative paths are
+		// placed under the logs directory of the config directory. The file is rotated
+		// once it grows too large.
+		LogFile string `json:"log_file" key:"log_file" yaml:"log_file" mapstructure:"log_file"`
+		// Color is when to color output: "auto" (only on terminals, honouring NO_COLOR
+		// and CLICOLOR_FORCE), "always" or "never"
+		Color string `json:"color" key:"color" yaml:"color" mapstructure:"color"`
 		// Connection settings
 		Connection Connection `json:"connection" key:"connection" yaml:"connection" mapstructure:"connection"`
+		// Trace settings for debugging API calls
+		Trace Trace `json:"trace" key:"trace" yaml:"trace" mapstructure:"trace"`
+		// Cache settings for listings of clusters, nodes and namespaces
+		Cache Cache `json:"cache" key:"cache" yaml:"cache" mapstructure:"cache"`
+		// Audit settings for the log of mutating operations
+		Audit Audit `json:"audit" key:"audit" yaml:"audit" mapstructure:"audit"`
+		// Upgrade settings for the upgrade command
+		Upgrade Upgrade `json:"upgrade" key:"upgrade" yaml:"upgrade" mapstructure:"upgrade"`
+		// Aliases are shortcuts for commands, e.g. "pods": "list pod -c prod-eks -n cedana".
+		// Arguments given after an alias are appended to its command, or substituted
+		// for $1, $2, etc. if it has any.
+		Aliases map[string]string `json:"aliases" key:"aliases" yaml:"aliases" mapstructure:"aliases"`
 	}
 
 	Connection struct {
@@ -17,5 +40,48 @@ type (
 		URL string `json:"url" key:"url" yaml:"url" mapstructure:"url" env_aliases:"CEDANA_URL"`
 		// AuthToken is your authentication token for the Cedana endpoint
 		AuthToken string `json:"auth_token" key:"auth_token" yaml:"auth_token" mapstructure:"auth_token" env_aliases:"CEDANA_AUTH_TOKEN"`
+		// Timeout is the maximum duration of a single request (e.g. "30s"), 0 for no timeout
+		Timeout time.Duration `json:"timeout" key:"timeout" yaml:"timeout" mapstructure:"timeout"`
+		// StrictVersion makes requests fail when the server reports this CLI is older than the
+		// minimum version it supports, checked before sending any mutating request. By default
+		// only a warning is printed.
+		StrictVersion bool `json:"strict_version" key:"strict_version" yaml:"strict_version" mapstructure:"strict_version"`
+	}
+
+	Trace struct {
+		// HTTP enables logging of every HTTP request and response (at debug level)
+		HTTP bool `json:"http" key:"http" yaml:"http" mapstructure:"http"`
+		// HARFile is a file to dump all HTTP exchanges to, in HAR format
+		HARFile string `json:"har_file" key:"har_file" yaml:"har_file" mapstructure:"har_file"`
+		// RedactFields are JSON and YAML body fields whose values are redacted from
+		// traces, in addition to the Authorization header which is always redacted
+		RedactFields []string `json:"redact_fields" key:"redact_fields" yaml:"redact_fields" mapstructure:"redact_fields"`
+		// StrictDecode makes responses containing fields unknown to this CLI fail to
+		// decode, listing the unknown fields. By default they are ignored and only
+		// logged at debug level.
+		StrictDecode bool `json:"strict_decode" key:"strict_decode" yaml:"strict_decode" mapstructure:"strict_decode"`
+	}
+
+	Cache struct {
+		// Enabled caches listings on disk, under the cache directory of the config directory
+		Enabled bool `json:"enabled" key:"enabled" yaml:"enabled" mapstructure:"enabled"`
+		// TTL is how long a cached listing is used by shell completion without asking the
+		// server (e.g. "5m"). Other listings, and completions once it has expired, always
+		// ask the server, revalidating the cached listing using its ETag, if any.
+		TTL time.Duration `json:"ttl" key:"ttl" yaml:"ttl" mapstructure:"ttl"`
+	}
+
+	Audit struct {
+		// Enabled records every mutating operation (create, delete, suspend, etc.) in
+		// the audit log under the config directory
+		Enabled bool `json:"enabled" key:"enabled" yaml:"enabled" mapstructure:"enabled"`
+		// Ship also sends each record to the server, for an audit trail across machines
+		Ship bool `json:"ship" key:"ship" yaml:"ship" mapstructure:"ship"`
+	}
+
+	Upgrade struct {
+		// IndexURL is the URL of the release index, a JSON list of releases in the
+		// format of the GitHub releases API
+		IndexURL string `json:"index_url" key:"index_url" yaml:"index_url" mapstructure:"index_url"`
 	}
 )
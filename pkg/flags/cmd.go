@@ -9,9 +9,88 @@ type Flag struct {
 
 var (
 	// Parent flags
-	ConfigFlag    = Flag{Full: "config"}
-	ConfigDirFlag = Flag{Full: "config-dir"}
+	ConfigFlag         = Flag{Full: "config"}
+	ConfigDirFlag      = Flag{Full: "config-dir"}
+	VerboseFlag        = Flag{Full: "verbose", Short: "v"}
+	QuietFlag          = Flag{Full: "quiet", Short: "q"}
+	TraceHTTPFlag      = Flag{Full: "trace-http"}
+	HARFileFlag        = Flag{Full: "har-file"}
+	RequestTimeoutFlag = Flag{Full: "request-timeout"}
+	StrictDecodeFlag   = Flag{Full: "strict-decode"}
+	NoCacheFlag        = Flag{Full: "no-cache"}
+	NoColorFlag        = Flag{Full: "no-color"}
+	StrictVersionFlag  = Flag{Full: "strict-version"}
 
 	ClusterFlag   = Flag{Full: "cluster", Short: "c"}
 	NamespaceFlag = Flag{Full: "namespace", Short: "n"}
+	OutputFlag    = Flag{Full: "output", Short: "o"}
+	FileFlag      = Flag{Full: "file", Short: "f"}
+	SelectorFlag  = Flag{Full: "selector", Short: "l"}
+
+	// List flags
+	LimitFlag         = Flag{Full: "limit"}
+	ContinueFlag      = Flag{Full: "continue"}
+	FieldSelectorFlag = Flag{Full: "field-selector"}
+
+	// API flags
+	HeaderFlag   = Flag{Full: "header", Short: "H"}
+	FieldFlag    = Flag{Full: "field"}
+	PaginateFlag = Flag{Full: "paginate"}
+
+	// Top flags
+	SortByFlag    = Flag{Full: "sort-by"}
+	WatchFlag     = Flag{Full: "watch", Short: "w"}
+	IntervalFlag  = Flag{Full: "interval"}
+	ThresholdFlag = Flag{Full: "threshold"}
+
+	// Cost flags
+	ByFlag       = Flag{Full: "by"}
+	LabelKeyFlag = Flag{Full: "label-key"}
+	SinceFlag    = Flag{Full: "since"}
+	UntilFlag    = Flag{Full: "until"}
+
+	// Estimate flags
+	CountFlag    = Flag{Full: "count"}
+	DurationFlag = Flag{Full: "duration"}
+
+	// Chaos flags
+	RandomFlag  = Flag{Full: "random"}
+	TimeoutFlag = Flag{Full: "timeout"}
+	DetachFlag  = Flag{Full: "detach"}
+
+	// Workload control flags
+	CheckpointFlag = Flag{Full: "checkpoint"}
+	FreshFlag      = Flag{Full: "fresh"}
+
+	// Pod flags
+	TargetNodeFlag = Flag{Full: "target-node"}
+	TailFlag       = Flag{Full: "tail"}
+
+	// Plugin flags
+	NameFlag   = Flag{Full: "name"}
+	ForceFlag  = Flag{Full: "force"}
+	Sha256Flag = Flag{Full: "sha256"}
+
+	// Version flags
+	ServerFlag = Flag{Full: "server"}
+
+	// History flags
+	ResourceFlag = Flag{Full: "resource"}
+
+	// Rollout flags
+	RevisionFlag   = Flag{Full: "revision"}
+	ToRevisionFlag = Flag{Full: "to-revision"}
+
+	// Rerun flags
+	SetFlag            = Flag{Full: "set"}
+	FromCheckpointFlag = Flag{Full: "from-checkpoint"}
+	DryRunFlag         = Flag{Full: "dry-run"}
+
+	// Upgrade flags
+	CheckFlag   = Flag{Full: "check"}
+	VersionFlag = Flag{Full: "version"}
+
+	// Convert flags
+	ImageFlag = Flag{Full: "image"}
+	QueueFlag = Flag{Full: "queue"}
 )
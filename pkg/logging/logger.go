@@ -1,11 +1,14 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/cedana/cedana-cli/pkg/config"
+	"github.com/cedana/cedana-cli/pkg/style"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
@@ -14,6 +17,12 @@ import (
 const (
 	LOG_TIME_FORMAT = time.TimeOnly
 	LOG_CALLER_SKIP = 3 // stack frame depth
+
+	FORMAT_CONSOLE = "console"
+	FORMAT_JSON    = "json"
+
+	LOG_FILE_MAX_SIZE    = 10 << 20 // bytes
+	LOG_FILE_MAX_BACKUPS = 3
 )
 
 type LineInfoHook struct{}
@@ -24,23 +33,91 @@ func (h LineInfoHook) Run(e *zerolog.Event, l zerolog.Level, msg string) {
 	}
 }
 
+var (
+	logLevel            = zerolog.Disabled
+	logFormat           = FORMAT_CONSOLE
+	logOut    io.Writer = os.Stderr // logs never go to stdout, to not mix with command output
+)
+
 func init() {
+	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 	SetLevel(config.Global.LogLevel)
 }
 
 func SetLevel(level string) {
 	var err error
-	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
-
-	logLevel, err := zerolog.ParseLevel(level)
+	logLevel, err = zerolog.ParseLevel(level)
 	if err != nil || level == "" { // allow turning off logging
 		logLevel = zerolog.Disabled
 	}
 
-	var output io.Writer = zerolog.ConsoleWriter{
-		Out:          os.Stdout,
-		TimeFormat:   LOG_TIME_FORMAT,
-		TimeLocation: time.Local,
+	setLogger()
+}
+
+// Level returns the log level in use, empty if logging is off
+func Level() string {
+	if logLevel == zerolog.Disabled {
+		return ""
+	}
+	return logLevel.String()
+}
+
+// SetOutput sets the format of log lines and where they are written.
+// If file is empty, logs are written to stderr. Relative file paths are
+// placed under the logs directory of the config directory.
+func SetOutput(format string, file string) error {
+	switch format {
+	case "", FORMAT_CONSOLE:
+		format = FORMAT_CONSOLE
+	case FORMAT_JSON:
+	default:
+		return fmt.Errorf("invalid log format %q, must be %s or %s", format, FORMAT_CONSOLE, FORMAT_JSON)
+	}
+
+	var out io.Writer = os.Stderr
+	if file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(config.Dir, config.LOG_DIR_NAME, file)
+		}
+		if err := os.MkdirAll(filepath.Dir(file), config.DIR_PERM); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		rotating, err := newRotatingFile(file, LOG_FILE_MAX_SIZE, LOG_FILE_MAX_BACKUPS)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = rotating
+	}
+
+	if previous, ok := logOut.(*rotatingFile); ok {
+		previous.Close()
+	}
+	logFormat = format
+	logOut = out
+
+	setLogger()
+	return nil
+}
+
+// ToStderr returns whether logs are written to stderr, rather than a file
+func ToStderr() bool {
+	_, toFile := logOut.(*rotatingFile)
+	return !toFile
+}
+
+func setLogger() {
+	output := logOut
+	if logFormat == FORMAT_CONSOLE {
+		color := false
+		if f, ok := logOut.(*os.File); ok {
+			color = style.ColorEnabled(f)
+		}
+		output = zerolog.ConsoleWriter{
+			Out:          logOut,
+			NoColor:      !color,
+			TimeFormat:   LOG_TIME_FORMAT,
+			TimeLocation: time.Local,
+		}
 	}
 
 	log.Logger = zerolog.New(output).
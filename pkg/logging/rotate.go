@@ -0,0 +1,95 @@
+package logging
+
+// A minimal size-based rotating log file. Once the file grows beyond the
+// max size, it is renamed to <file>.1 (shifting older backups up to the
+// max number kept) and a fresh file is started.
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cedana/cedana-cli/pkg/config"
+)
+
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > r.maxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, config.FILE_PERM)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(backupName(r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i > 0; i-- {
+		os.Rename(backupName(r.path, i), backupName(r.path, i+1))
+	}
+	if r.maxBackups > 0 {
+		if err := os.Rename(r.path, backupName(r.path, 1)); err != nil {
+			return err
+		}
+	} else {
+		os.Remove(r.path)
+	}
+
+	return r.open()
+}
+
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
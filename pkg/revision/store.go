@@ -0,0 +1,143 @@
+package revision
+
+// Local store of workload revisions, for servers that don't keep them. Each
+// workload has a JSON file holding its latest revisions, keyed by cluster,
+// namespace and name.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DIR_NAME  = "revisions"
+	DIR_PERM  = 0o755
+	FILE_PERM = 0o600
+
+	// HISTORY_LIMIT is the number of revisions kept per workload
+	HISTORY_LIMIT = 10
+)
+
+// Revision is a payload a workload was submitted with
+type Revision struct {
+	Revision    int       `json:"Revision"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+	ContentType string    `json:"ContentType"`
+	Payload     []byte    `json:"Payload"`
+}
+
+// Key identifies a workload
+type Key struct {
+	Cluster   string
+	Namespace string
+	Name      string
+}
+
+func (k Key) String() string {
+	return k.Cluster + "/" + k.Namespace + "/" + k.Name
+}
+
+// Store keeps revisions under a directory
+type Store struct {
+	Dir string
+}
+
+var mu sync.Mutex
+
+// NewStore returns the store under a config directory
+func NewStore(configDir string) *Store {
+	return &Store{Dir: filepath.Join(configDir, DIR_NAME)}
+}
+
+func (s *Store) path(key Key) string {
+	namespace := key.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return filepath.Join(s.Dir, key.Cluster, namespace, key.Name+".json")
+}
+
+// List returns the revisions of a workload, oldest first. A workload with no
+// stored revisions has none.
+func (s *Store) List(key Key) ([]Revision, error) {
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revisions []Revision
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		return nil, fmt.Errorf("revisions of %s are corrupt: %v", key, err)
+	}
+	return revisions, nil
+}
+
+// Add stores a new revision of a workload, numbered after its latest one, and
+// drops the oldest revisions beyond HISTORY_LIMIT
+func (s *Store) Add(key Key, payload []byte, contentType string) (*Revision, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	revisions, err := s.List(key)
+	if err != nil {
+		return nil, err
+	}
+	number := 1
+	if len(revisions) > 0 {
+		number = revisions[len(revisions)-1].Revision + 1
+	}
+	revision := Revision{
+		Revision:    number,
+		CreatedAt:   time.Now(),
+		ContentType: contentType,
+		Payload:     payload,
+	}
+	revisions = append(revisions, revision)
+	if len(revisions) > HISTORY_LIMIT {
+		revisions = revisions[len(revisions)-HISTORY_LIMIT:]
+	}
+
+	data, err := json.MarshalIndent(revisions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), DIR_PERM); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".revisions-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp.Name(), FILE_PERM); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Find returns the revision with the given number from a list of revisions
+func Find(revisions []Revision, number int) (*Revision, bool) {
+	for i := range revisions {
+		if revisions[i].Revision == number {
+			return &revisions[i], true
+		}
+	}
+	return nil, false
+}
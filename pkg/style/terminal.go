@@ -0,0 +1,80 @@
+package style
+
+// Terminal detection, deciding whether output gets colors and unicode marks.
+// Colors follow the NO_COLOR (https://no-color.org) and CLICOLOR_FORCE
+// conventions unless set explicitly.
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	COLOR_AUTO   = "auto"
+	COLOR_ALWAYS = "always"
+	COLOR_NEVER  = "never"
+)
+
+var colorMode = COLOR_AUTO
+
+func init() {
+	SetColor(COLOR_AUTO)
+}
+
+// SetColor sets when output is colored: "auto" (only on terminals), "always" or
+// "never". Unicode marks are used on terminals, or whenever colors are forced,
+// and replaced with ASCII fallbacks otherwise.
+func SetColor(mode string) error {
+	switch mode {
+	case "":
+		mode = COLOR_AUTO
+	case COLOR_AUTO, COLOR_ALWAYS, COLOR_NEVER:
+	default:
+		return fmt.Errorf("invalid color mode %q, must be %s, %s or %s", mode, COLOR_AUTO, COLOR_ALWAYS, COLOR_NEVER)
+	}
+	colorMode = mode
+
+	colors := ColorEnabled(os.Stdout)
+	if colors {
+		text.EnableColors()
+	} else {
+		text.DisableColors()
+	}
+	setMarks(colors || IsTerminal(os.Stdout))
+	return nil
+}
+
+// ColorEnabled returns whether output written to f should be colored
+func ColorEnabled(f *os.File) bool {
+	switch colorMode {
+	case COLOR_ALWAYS:
+		return true
+	case COLOR_NEVER:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return IsTerminal(f)
+}
+
+// IsTerminal returns whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// setMarks picks the marks used in output. Tables need no fallback, as
+// TableStyle draws no borders.
+func setMarks(unicode bool) {
+	if unicode {
+		TickMark, BulletMark, CrossMark, DashMark = "✔", "•", "✖", "—"
+	} else {
+		TickMark, BulletMark, CrossMark, DashMark = "+", "*", "x", "-"
+	}
+}
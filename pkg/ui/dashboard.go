@@ -0,0 +1,512 @@
+package ui
+
+// Full-screen terminal dashboard to browse the nodes, pods and workloads of a
+// cluster and act on them. All data is fetched through the client package, in
+// the background so the interface never blocks on the network.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	LOG_TAIL_LINES = 200
+
+	PAGE_MAIN    = "main"
+	PAGE_PICKER  = "picker"
+	PAGE_CONFIRM = "confirm"
+)
+
+// sidePane is what the side pane shows for the selected row
+type sidePane int
+
+const (
+	SIDE_EVENTS sidePane = iota
+	SIDE_LOGS
+)
+
+// Options configure the dashboard
+type Options struct {
+	// ClusterName to open, if empty the cluster is picked interactively
+	ClusterName string
+	Namespace   string
+	// Interval between automatic refreshes, 0 to only refresh on demand
+	Interval time.Duration
+}
+
+type dashboard struct {
+	ctx  context.Context
+	app  *tview.Application
+	opts Options
+
+	pages  *tview.Pages
+	header *tview.TextView
+	table  *tview.Table
+	side   *tview.TextView
+	footer *tview.TextView
+
+	// Fields below are only accessed from the UI goroutine
+	clusterName string
+	namespace   string
+	view        *view
+	rows        []row
+	sidePane    sidePane
+	status      string
+	// generation is bumped whenever the side pane is reloaded, so stale loads are dropped
+	generation int
+}
+
+// Run shows the dashboard until the user quits or ctx is canceled
+func Run(ctx context.Context, opts Options) error {
+	d := &dashboard{
+		ctx:         ctx,
+		app:         tview.NewApplication(),
+		opts:        opts,
+		clusterName: opts.ClusterName,
+		namespace:   opts.Namespace,
+		view:        nodesView,
+	}
+	if d.namespace == "" {
+		d.namespace = "default"
+	}
+	d.layout()
+
+	go func() {
+		<-ctx.Done()
+		d.app.Stop()
+	}()
+	if opts.Interval > 0 {
+		go d.autoRefresh(opts.Interval)
+	}
+
+	if d.clusterName == "" {
+		d.pickCluster()
+	} else {
+		d.refresh()
+	}
+
+	return d.app.Run()
+}
+
+func (d *dashboard) layout() {
+	d.header = tview.NewTextView().SetDynamicColors(true)
+
+	d.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	d.table.SetBorder(true)
+	d.table.SetSelectionChangedFunc(func(row, column int) {
+		d.loadSide()
+	})
+
+	d.side = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	d.side.SetBorder(true)
+
+	d.footer = tview.NewTextView().SetDynamicColors(true)
+
+	body := tview.NewFlex().
+		AddItem(d.table, 0, 3, true).
+		AddItem(d.side, 0, 2, false)
+
+	main := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(d.header, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(d.footer, 1, 0, false)
+
+	d.pages = tview.NewPages().AddPage(PAGE_MAIN, main, true, true)
+	d.app.SetRoot(d.pages, true).SetInputCapture(d.handleKey)
+	d.render()
+}
+
+// handleKey handles keys on the main page. Pickers and confirmations handle
+// their own keys.
+func (d *dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if name, _ := d.pages.GetFrontPage(); name != PAGE_MAIN {
+		if event.Key() == tcell.KeyEscape && name == PAGE_PICKER && d.clusterName != "" {
+			d.pages.RemovePage(PAGE_PICKER)
+			return nil
+		}
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyCtrlR:
+		d.refresh()
+		return nil
+	case tcell.KeyTab:
+		d.switchView(views[(d.viewIndex()+1)%len(views)])
+		return nil
+	case tcell.KeyEscape:
+		d.app.Stop()
+		return nil
+	}
+
+	switch key := event.Rune(); key {
+	case 'q':
+		d.app.Stop()
+	case '1', '2', '3':
+		d.switchView(views[key-'1'])
+	case 'C':
+		d.pickCluster()
+	case 'N':
+		d.pickNamespace()
+	case 'e':
+		d.sidePane = SIDE_EVENTS
+		d.loadSide()
+	case 'l':
+		if d.view.hasLogs {
+			d.sidePane = SIDE_LOGS
+			d.loadSide()
+		}
+	default:
+		for _, a := range d.view.actions {
+			if a.key == key {
+				d.confirm(a)
+				return nil
+			}
+		}
+		return event
+	}
+	return nil
+}
+
+func (d *dashboard) viewIndex() int {
+	for i, v := range views {
+		if v == d.view {
+			return i
+		}
+	}
+	return 0
+}
+
+func (d *dashboard) switchView(v *view) {
+	if v == d.view {
+		return
+	}
+	d.view = v
+	d.rows = nil
+	if !v.hasLogs {
+		d.sidePane = SIDE_EVENTS
+	}
+	d.render()
+	d.refresh()
+}
+
+// render redraws everything but the side pane from the current state
+func (d *dashboard) render() {
+	var tabs []string
+	for i, v := range views {
+		tab := fmt.Sprintf("[%d] %s", i+1, v.title)
+		if v == d.view {
+			tab = style.InfoColors.Sprint(tab)
+		}
+		tabs = append(tabs, tab)
+	}
+	header := fmt.Sprintf(" Cluster: %s  Namespace: %s   %s",
+		style.PositiveColors.Sprint(valueOr(d.clusterName, style.DashMark)),
+		valueOr(d.namespace, style.DashMark),
+		strings.Join(tabs, "  "),
+	)
+	d.header.SetText(tview.TranslateANSI(tview.Escape(header)))
+
+	selected, _ := d.table.GetSelection()
+	selectedName := ""
+	if selected > 0 && selected <= len(d.rows) {
+		selectedName = d.rows[selected-1].name
+	}
+
+	d.table.Clear()
+	d.table.SetTitle(fmt.Sprintf(" %s (%d) ", d.view.title, len(d.rows)))
+	for column, title := range d.view.header {
+		d.table.SetCell(0, column, tview.NewTableCell(strings.ToUpper(title)).
+			SetSelectable(false).
+			SetAttributes(tcell.AttrBold).
+			SetExpansion(1))
+	}
+	newSelection := 1
+	for i, r := range d.rows {
+		for column, cell := range r.cells {
+			d.table.SetCell(i+1, column, tview.NewTableCell(tview.TranslateANSI(tview.Escape(cell))).SetExpansion(1))
+		}
+		if r.name == selectedName {
+			newSelection = i + 1
+		}
+	}
+	if len(d.rows) > 0 {
+		d.table.Select(newSelection, 0)
+	}
+
+	hints := []string{"tab/1-3 view", "C cluster", "N namespace", "e events"}
+	if d.view.hasLogs {
+		hints = append(hints, "l logs")
+	}
+	for _, a := range d.view.actions {
+		hints = append(hints, fmt.Sprintf("%c %s", a.key, a.label))
+	}
+	hints = append(hints, "ctrl-r refresh", "q quit")
+	footer := " " + style.DisabledColors.Sprint(strings.Join(hints, " · "))
+	if d.status != "" {
+		footer = " " + d.status + "  " + footer
+	}
+	d.footer.SetText(tview.TranslateANSI(tview.Escape(footer)))
+}
+
+// refresh reloads the rows of the current view in the background
+func (d *dashboard) refresh() {
+	if d.clusterName == "" {
+		return
+	}
+	v, clusterName, namespace := d.view, d.clusterName, d.namespace
+	go func() {
+		rows, err := v.load(d.ctx, clusterName, namespace)
+		d.app.QueueUpdateDraw(func() {
+			if v != d.view || clusterName != d.clusterName || namespace != d.namespace {
+				return // user moved on while loading
+			}
+			if err != nil {
+				d.setStatus(style.NegativeColors.Sprint(err.Error()))
+				return
+			}
+			d.rows = rows
+			d.render()
+			d.loadSide()
+		})
+	}()
+}
+
+func (d *dashboard) autoRefresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.app.QueueUpdate(d.refresh)
+		}
+	}
+}
+
+func (d *dashboard) setStatus(status string) {
+	d.status = status
+	d.render()
+}
+
+func (d *dashboard) selectedRow() (row, bool) {
+	selected, _ := d.table.GetSelection()
+	if selected < 1 || selected > len(d.rows) {
+		return row{}, false
+	}
+	return d.rows[selected-1], true
+}
+
+// loadSide loads the events or logs of the selected row into the side pane
+func (d *dashboard) loadSide() {
+	d.generation++
+	generation := d.generation
+
+	r, ok := d.selectedRow()
+	if !ok {
+		d.side.SetTitle("")
+		d.side.SetText("")
+		return
+	}
+
+	pane, kind, clusterName := d.sidePane, d.view.kind, d.clusterName
+	if pane == SIDE_LOGS {
+		d.side.SetTitle(fmt.Sprintf(" Logs: %s ", r.name))
+	} else {
+		d.side.SetTitle(fmt.Sprintf(" Events: %s ", r.name))
+	}
+	d.side.SetText(tview.TranslateANSI(style.DisabledColors.Sprint("Loading...")))
+
+	go func() {
+		var content string
+		var err error
+		if pane == SIDE_LOGS {
+			content, err = client.GetPodLogs(d.ctx, clusterName, r.namespace, r.name, LOG_TAIL_LINES)
+			content = tview.Escape(content)
+		} else {
+			var events []client.Event
+			events, err = client.GetEvents(d.ctx, clusterName, r.namespace, kind, r.name)
+			content = eventsStr(events)
+		}
+		if err != nil {
+			content = style.NegativeColors.Sprint(err.Error())
+		}
+
+		d.app.QueueUpdateDraw(func() {
+			if generation != d.generation {
+				return
+			}
+			d.side.SetText(tview.TranslateANSI(content))
+			if pane == SIDE_LOGS {
+				d.side.ScrollToEnd()
+			} else {
+				d.side.ScrollToBeginning()
+			}
+		})
+	}()
+}
+
+func eventsStr(events []client.Event) string {
+	if len(events) == 0 {
+		return style.DisabledColors.Sprint("No events")
+	}
+	var b strings.Builder
+	for _, e := range events {
+		reason := e.Reason
+		if e.Type == "Warning" {
+			reason = style.WarningColors.Sprint(reason)
+		}
+		count := ""
+		if e.Count > 1 {
+			count = fmt.Sprintf(" (x%d)", e.Count)
+		}
+		fmt.Fprintf(&b, "%s %s%s\n%s\n\n",
+			style.DisabledColors.Sprint(ageStr(e.LastSeen)),
+			reason,
+			count,
+			tview.Escape(e.Message),
+		)
+	}
+	return b.String()
+}
+
+// confirm asks before running the action on the selected row
+func (d *dashboard) confirm(a action) {
+	r, ok := d.selectedRow()
+	if !ok {
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s %s %s?", capitalize(a.label), strings.ToLower(d.view.kind), r.name)).
+		AddButtons([]string{"Cancel", capitalize(a.label)}).
+		SetDoneFunc(func(index int, label string) {
+			d.pages.RemovePage(PAGE_CONFIRM)
+			d.app.SetFocus(d.table)
+			if index != 1 {
+				return
+			}
+			d.run(a, r)
+		})
+	d.pages.AddPage(PAGE_CONFIRM, modal, false, true)
+	d.app.SetFocus(modal)
+}
+
+func (d *dashboard) run(a action, r row) {
+	d.setStatus(style.InfoColors.Sprintf("Running %s on %s...", a.label, r.name))
+	clusterName := d.clusterName
+	go func() {
+		_, err := a.run(d.ctx, clusterName, r)
+		d.app.QueueUpdateDraw(func() {
+			if err != nil {
+				d.setStatus(style.NegativeColors.Sprintf("Failed to %s %s: %v", a.label, r.name, err))
+				return
+			}
+			d.setStatus(style.PositiveColors.Sprintf("%s %s: done", capitalize(a.label), r.name))
+			d.refresh()
+		})
+	}()
+}
+
+// pick shows a list to choose from, loaded in the background
+func (d *dashboard) pick(title string, load func() ([]string, error), picked func(choice string)) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true)
+	list.SetTitle(" " + title + " ")
+	list.AddItem(tview.TranslateANSI(style.DisabledColors.Sprint("Loading...")), "", 0, nil)
+
+	width, height := 50, 15
+	modal := tview.NewGrid().
+		SetColumns(0, width, 0).
+		SetRows(0, height, 0).
+		AddItem(list, 1, 1, 1, 1, 0, 0, true)
+
+	d.pages.RemovePage(PAGE_PICKER)
+	d.pages.AddPage(PAGE_PICKER, modal, true, true)
+	d.app.SetFocus(list)
+
+	go func() {
+		choices, err := load()
+		d.app.QueueUpdateDraw(func() {
+			list.Clear()
+			if err != nil {
+				list.AddItem(tview.TranslateANSI(style.NegativeColors.Sprint(tview.Escape(err.Error()))), "", 0, nil)
+				return
+			}
+			if len(choices) == 0 {
+				list.AddItem("Nothing to choose from", "", 0, nil)
+				return
+			}
+			for _, choice := range choices {
+				list.AddItem(tview.Escape(choice), "", 0, func() {
+					d.pages.RemovePage(PAGE_PICKER)
+					d.app.SetFocus(d.table)
+					picked(choice)
+				})
+			}
+		})
+	}()
+}
+
+func (d *dashboard) pickCluster() {
+	d.pick("Cluster", func() ([]string, error) {
+		clusters, err := client.ListClusters(d.ctx, client.ListOptions{SortBy: "name"})
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(clusters.Items))
+		for _, c := range clusters.Items {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	}, func(choice string) {
+		d.clusterName = choice
+		d.rows = nil
+		d.status = ""
+		d.render()
+		d.refresh()
+	})
+}
+
+func (d *dashboard) pickNamespace() {
+	if d.clusterName == "" {
+		return
+	}
+	clusterName := d.clusterName
+	d.pick("Namespace", func() ([]string, error) {
+		return client.ListNamespaces(d.ctx, clusterName)
+	}, func(choice string) {
+		d.namespace = choice
+		d.rows = nil
+		d.render()
+		d.refresh()
+	})
+}
+
+func valueOr(s string, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,209 @@
+package ui
+
+// Resources browsable in the dashboard, and the actions available on them.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+	"github.com/cedana/cedana-cli/pkg/style"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// view is a table of one kind of resource
+type view struct {
+	title string
+	// kind is the kind of object, as used to fetch events
+	kind       string
+	namespaced bool
+	header     []string
+	load       func(ctx context.Context, clusterName, namespace string) ([]row, error)
+	actions    []action
+	// hasLogs is set if the resource has logs to show in the side pane
+	hasLogs bool
+}
+
+// row is a single resource shown in a view
+type row struct {
+	name      string
+	namespace string
+	kind      string // kind of workload, if any
+	cells     []string
+}
+
+// action is an operation triggered on the selected row by a key, after confirmation
+type action struct {
+	key   rune
+	label string
+	run   func(ctx context.Context, clusterName string, r row) (string, error)
+}
+
+var views = []*view{nodesView, podsView, workloadsView}
+
+var nodesView = &view{
+	title:  "Nodes",
+	kind:   "Node",
+	header: []string{"Name", "Status", "Instance Type", "Compute Type", "Region", "Age"},
+	load: func(ctx context.Context, clusterName, namespace string) ([]row, error) {
+		nodes, err := client.GetClusterNodes(ctx, clusterName, client.ListOptions{SortBy: "name"})
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]row, 0, len(nodes.Items))
+		for _, n := range nodes.Items {
+			rows = append(rows, row{
+				name:  n.Name,
+				cells: []string{n.Name, statusStr(n.Status), n.InstanceType, n.ComputeType, n.Region, ageStr(n.CreatedAt)},
+			})
+		}
+		return rows, nil
+	},
+}
+
+var podsView = &view{
+	title:      "Pods",
+	kind:       "Pod",
+	namespaced: true,
+	hasLogs:    true,
+	header:     []string{"Name", "Status", "Node", "Restarts", "Owner", "Age"},
+	load: func(ctx context.Context, clusterName, namespace string) ([]row, error) {
+		pods, err := client.GetClusterPods(ctx, clusterName, namespace, client.ListOptions{SortBy: "name"})
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]row, 0, len(pods.Items))
+		for _, p := range pods.Items {
+			rows = append(rows, row{
+				name:      p.Name,
+				namespace: p.Namespace,
+				cells: []string{
+					p.Name,
+					statusStr(p.Status),
+					p.NodeID,
+					fmt.Sprint(p.Metadata.RestartCount),
+					p.Metadata.Owner,
+					ageStr(p.CreatedAt),
+				},
+			})
+		}
+		return rows, nil
+	},
+	actions: []action{
+		{
+			key:   'c',
+			label: "checkpoint",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.CheckpointPod(ctx, clusterName, r.namespace, r.name)
+			},
+		},
+		{
+			key:   'm',
+			label: "migrate",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.MigratePod(ctx, clusterName, r.namespace, r.name, "")
+			},
+		},
+	},
+}
+
+var workloadsView = &view{
+	title:      "Workloads",
+	kind:       "Workload",
+	namespaced: true,
+	header:     []string{"Name", "Kind", "Status", "Queue", "Priority", "Age"},
+	load: func(ctx context.Context, clusterName, namespace string) ([]row, error) {
+		workloads, err := client.GetClusterWorkloads(ctx, clusterName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]row, 0, len(workloads))
+		for _, w := range workloads {
+			if w.Namespace == "" {
+				w.Namespace = namespace
+			}
+			rows = append(rows, row{
+				name:      w.Name,
+				namespace: w.Namespace,
+				kind:      w.Kind,
+				cells:     []string{w.Name, w.Kind, statusStr(w.Status), w.Queue, w.PriorityClass, ageStr(w.CreatedAt)},
+			})
+		}
+		return rows, nil
+	},
+	actions: []action{
+		{
+			key:   's',
+			label: "suspend",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.SuspendWorkload(ctx, clusterName, r.namespace, r.name, false)
+			},
+		},
+		{
+			key:   'c',
+			label: "checkpoint and suspend",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.SuspendWorkload(ctx, clusterName, r.namespace, r.name, true)
+			},
+		},
+		{
+			key:   'r',
+			label: "resume",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.ResumeWorkload(ctx, clusterName, r.namespace, r.name, true)
+			},
+		},
+		{
+			key:   'd',
+			label: "delete",
+			run: func(ctx context.Context, clusterName string, r row) (string, error) {
+				return client.DeleteWorkloadByName(ctx, clusterName, r.namespace, r.kind, r.name)
+			},
+		},
+	},
+}
+
+////////////////////
+///// Helpers //////
+////////////////////
+
+// statusColors picks the colors of a status from the style palette
+func statusColors(status string) text.Colors {
+	switch strings.ToLower(status) {
+	case "running", "ready", "active", "succeeded", "completed", "admitted", "restored":
+		return style.PositiveColors
+	case "failed", "error", "notready", "crashloopbackoff", "evicted":
+		return style.NegativeColors
+	case "pending", "suspended", "queued", "checkpointing", "checkpointed", "restoring", "terminating":
+		return style.WarningColors
+	default:
+		return nil
+	}
+}
+
+func statusStr(status string) string {
+	if colors := statusColors(status); colors != nil {
+		return colors.Sprint(status)
+	}
+	return status
+}
+
+// ageStr formats the time since t like kubectl does, e.g. "5m" or "3d"
+func ageStr(t time.Time) string {
+	if t.IsZero() {
+		return style.DashMark
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,274 @@
+package upgrade
+
+// Self-update from release artifacts published by goreleaser. The release
+// index is a JSON list of releases in the format of the GitHub releases API,
+// so any server, including a local stand-in, can serve it. Archive and
+// checksum names follow the goreleaser defaults used in .goreleaser.yaml.
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cedana/cedana-cli/client"
+)
+
+const (
+	PROJECT_NAME   = "cedana-cli"
+	CHECKSUMS_FILE = "checksums.txt"
+	BINARY_PERM    = 0o755
+
+	// DOWNLOAD_TIMEOUT bounds each request to the release index, including
+	// reading the downloaded archive
+	DOWNLOAD_TIMEOUT = 5 * time.Minute
+)
+
+var httpClient = &http.Client{Timeout: DOWNLOAD_TIMEOUT}
+
+type (
+	// Release is a published release in the index
+	Release struct {
+		Tag        string  `json:"tag_name"`
+		Draft      bool    `json:"draft"`
+		Prerelease bool    `json:"prerelease"`
+		Assets     []Asset `json:"assets"`
+	}
+
+	// Asset is a file attached to a release
+	Asset struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	}
+)
+
+// Version returns the version of the release, without the "v" prefix of its tag
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset with the given name, if any
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// resolve makes asset URLs relative to the index absolute
+func (r *Release) resolve(base *url.URL) *Release {
+	for i, asset := range r.Assets {
+		if ref, err := url.Parse(asset.URL); err == nil {
+			r.Assets[i].URL = base.ResolveReference(ref).String()
+		}
+	}
+	return r
+}
+
+// ArchiveName returns the name of the goreleaser archive of a version for the
+// current OS and architecture, e.g. "cedana-cli_0.4.2_linux_amd64.tar.gz"
+func ArchiveName(version string) string {
+	return fmt.Sprintf("%s_%s_%s_%s.tar.gz", PROJECT_NAME, strings.TrimPrefix(version, "v"), runtime.GOOS, runtime.GOARCH)
+}
+
+// FindRelease fetches the index and returns the release with the given
+// version, or the highest published version if version is empty. Drafts and
+// prereleases are only returned when pinned.
+func FindRelease(ctx context.Context, indexURL, version string) (*Release, error) {
+	body, err := download(ctx, indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching release index: %v", err)
+	}
+	defer body.Close()
+
+	var releases []Release
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("error decoding release index: %v", err)
+	}
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != "" {
+		for i := range releases {
+			if releases[i].Version() == strings.TrimPrefix(version, "v") {
+				return releases[i].resolve(base), nil
+			}
+		}
+		return nil, fmt.Errorf("release %s not found in %s", version, indexURL)
+	}
+
+	// the order of the index is not relied upon, as releases of older
+	// versions may be published after newer ones
+	var latest *Release
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		if latest == nil {
+			latest = release
+			continue
+		}
+		if order, err := client.CompareVersions(release.Version(), latest.Version()); err == nil && order > 0 {
+			latest = release
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no published release found in %s", indexURL)
+	}
+	return latest.resolve(base), nil
+}
+
+// Upgrade installs the release over the binary at path, which runs the
+// current version. It does nothing and returns false if the release is the
+// current version, unless force is set.
+func Upgrade(ctx context.Context, release *Release, current, path string, force bool) (bool, error) {
+	if order, err := client.CompareVersions(release.Version(), current); err == nil && order == 0 && !force {
+		return false, nil
+	}
+	if err := Install(ctx, release, path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Install downloads the archive of the release for the current OS and
+// architecture, verifies it against the release's checksums and atomically
+// replaces the binary at path with the one it contains
+func Install(ctx context.Context, release *Release, path string) error {
+	name := ArchiveName(release.Version())
+	archive, ok := release.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no archive for %s/%s (%s)", release.Tag, runtime.GOOS, runtime.GOARCH, name)
+	}
+	checksums, ok := release.Asset(CHECKSUMS_FILE)
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify the download with", release.Tag, CHECKSUMS_FILE)
+	}
+
+	want, err := fetchChecksum(ctx, checksums.URL, name)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmpArchive, err := os.CreateTemp("", ".upgrade-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpArchive.Name())
+	defer tmpArchive.Close()
+
+	body, err := download(ctx, archive.URL)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %v", name, err)
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpArchive, hash), body); err != nil {
+		return fmt.Errorf("error downloading %s: %v", name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+
+	if _, err := tmpArchive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// extract next to the binary, so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(dir, ".upgrade-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s, you may need to run with sudo: %v", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := extractBinary(tmpArchive, tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error extracting %s: %v", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), BINARY_PERM); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fetchChecksum returns the SHA-256 of name listed in a goreleaser checksums file
+func fetchChecksum(ctx context.Context, checksumsURL, name string) (string, error) {
+	body, err := download(ctx, checksumsURL)
+	if err != nil {
+		return "", fmt.Errorf("error downloading %s: %v", CHECKSUMS_FILE, err)
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading %s: %v", CHECKSUMS_FILE, err)
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", CHECKSUMS_FILE, name)
+}
+
+// extractBinary copies the CLI binary out of a tar.gz archive
+func extractBinary(archive io.Reader, w io.Writer) error {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("archive has no %s binary", PROJECT_NAME)
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == PROJECT_NAME {
+			_, err := io.Copy(w, reader)
+			return err
+		}
+	}
+}
+
+func download(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s returned %s", rawURL, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,203 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// releaseServer serves a release index, with the archive and checksums of
+// each release, and counts the requests made for each path
+type releaseServer struct {
+	*httptest.Server
+	files map[string][]byte
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newReleaseServer(t *testing.T) *releaseServer {
+	s := &releaseServer{files: map[string][]byte{}, requests: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.mu.Unlock()
+		data, ok := s.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// publish adds a release whose archive contains binary to the index. If
+// checksum is empty, the actual checksum of the archive is listed.
+func (s *releaseServer) publish(t *testing.T, release Release, binary, checksum string) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: PROJECT_NAME, Mode: 0o755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(binary))
+	tw.Close()
+	gz.Close()
+
+	if checksum == "" {
+		sum := sha256.Sum256(archive.Bytes())
+		checksum = hex.EncodeToString(sum[:])
+	}
+	name := ArchiveName(release.Version())
+	dir := "/download/" + release.Tag + "/"
+	s.files[dir+name] = archive.Bytes()
+	s.files[dir+CHECKSUMS_FILE] = []byte(fmt.Sprintf("%s  %s\n", checksum, name))
+	release.Assets = []Asset{
+		{Name: name, URL: dir + name},
+		{Name: CHECKSUMS_FILE, URL: dir + CHECKSUMS_FILE},
+	}
+
+	var releases []Release
+	if index, ok := s.files["/releases"]; ok {
+		if err := json.Unmarshal(index, &releases); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index, err := json.Marshal(append(releases, release))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.files["/releases"] = index
+}
+
+func (s *releaseServer) requested(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func TestFindRelease(t *testing.T) {
+	server := newReleaseServer(t)
+	for _, release := range []Release{
+		{Tag: "v0.4.2"},
+		{Tag: "v0.5.1"},
+		{Tag: "v0.10.0"},
+		{Tag: "v0.5.0"},
+		{Tag: "v0.11.0", Draft: true},
+		{Tag: "v0.12.0-rc1", Prerelease: true},
+	} {
+		server.publish(t, release, "binary", "")
+	}
+	indexURL := server.URL + "/releases"
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "", want: "v0.10.0"},
+		{version: "v0.4.2", want: "v0.4.2"},
+		{version: "0.5.0", want: "v0.5.0"},
+		{version: "v0.12.0-rc1", want: "v0.12.0-rc1"},
+	}
+	for _, tt := range tests {
+		release, err := FindRelease(context.Background(), indexURL, tt.version)
+		if err != nil {
+			t.Fatalf("FindRelease(%q): %v", tt.version, err)
+		}
+		if release.Tag != tt.want {
+			t.Errorf("FindRelease(%q) = %s, want %s", tt.version, release.Tag, tt.want)
+		}
+		if archive, ok := release.Asset(ArchiveName(release.Version())); !ok || !strings.HasPrefix(archive.URL, server.URL) {
+			t.Errorf("FindRelease(%q) archive URL %q not resolved against the index", tt.version, archive.URL)
+		}
+	}
+
+	if _, err := FindRelease(context.Background(), indexURL, "v9.9.9"); err == nil {
+		t.Error("FindRelease of a missing version succeeded")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	server := newReleaseServer(t)
+	server.publish(t, Release{Tag: "v0.5.0"}, "new binary", "")
+	server.publish(t, Release{Tag: "v0.6.0"}, "tampered binary", strings.Repeat("0", 64))
+	indexURL := server.URL + "/releases"
+
+	path := filepath.Join(t.TempDir(), PROJECT_NAME)
+	if err := os.WriteFile(path, []byte("old binary"), BINARY_PERM); err != nil {
+		t.Fatal(err)
+	}
+	assertBinary := func(want string) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("binary is %q, want %q", data, want)
+		}
+	}
+
+	t.Run("same version", func(t *testing.T) {
+		release, err := FindRelease(context.Background(), indexURL, "v0.5.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		installed, err := Upgrade(context.Background(), release, "v0.5.0", path, false)
+		if err != nil || installed {
+			t.Fatalf("Upgrade to the current version = %v, %v, want a no-op", installed, err)
+		}
+		if n := server.requested(release.Assets[0].URL[len(server.URL):]); n != 0 {
+			t.Errorf("archive downloaded %d times, want none", n)
+		}
+		assertBinary("old binary")
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		release, err := FindRelease(context.Background(), indexURL, "v0.6.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Install(context.Background(), release, path)
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("Install with a wrong checksum = %v, want a checksum mismatch", err)
+		}
+		assertBinary("old binary")
+		if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".upgrade-*")); len(matches) > 0 {
+			t.Errorf("temporary files left behind: %v", matches)
+		}
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		release, err := FindRelease(context.Background(), indexURL, "v0.5.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		installed, err := Upgrade(context.Background(), release, "v0.4.2", path, false)
+		if err != nil || !installed {
+			t.Fatalf("Upgrade from an older version = %v, %v, want installed", installed, err)
+		}
+		assertBinary("new binary")
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != BINARY_PERM {
+			t.Errorf("binary mode = %v, want %v", info.Mode().Perm(), os.FileMode(BINARY_PERM))
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package workload
+
+// Types for the workload payload accepted by the Cedana API. A payload is an
+// envelope holding the target cluster name and a kubernetes batch/v1 Job.
+// Only the subset of the Job schema used by the CLI is modelled here.
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RESOURCE_CPU    = "cpu"
+	RESOURCE_MEMORY = "memory"
+	RESOURCE_GPU    = "nvidia.com/gpu"
+
+	QUEUE_NAME_LABEL = "kueue.x-k8s.io/queue-name"
+)
+
+type (
+	// Manifest is the envelope expected by the workload endpoints
+	Manifest struct {
+		ClusterName string `json:"cluster_name" yaml:"cluster_name"`
+		Workload    Job    `json:"workload" yaml:"workload"`
+	}
+
+	Job struct {
+		APIVersion string     `json:"apiVersion" yaml:"apiVersion"`
+		Kind       string     `json:"kind" yaml:"kind"`
+		Metadata   ObjectMeta `json:"metadata" yaml:"metadata"`
+		Spec       JobSpec    `json:"spec" yaml:"spec"`
+	}
+
+	ObjectMeta struct {
+		Name        string            `json:"name,omitempty" yaml:"name,omitempty"`
+		Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+		Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	}
+
+	JobSpec struct {
+		Parallelism           *int32          `json:"parallelism,omitempty" yaml:"parallelism,omitempty"`
+		Completions           *int32          `json:"completions,omitempty" yaml:"completions,omitempty"`
+		CompletionMode        string          `json:"completionMode,omitempty" yaml:"completionMode,omitempty"`
+		ActiveDeadlineSeconds *int64          `json:"activeDeadlineSeconds,omitempty" yaml:"activeDeadlineSeconds,omitempty"`
+		BackoffLimit          *int32          `json:"backoffLimit,omitempty" yaml:"backoffLimit,omitempty"`
+		BackoffLimitPerIndex  *int32          `json:"backoffLimitPerIndex,omitempty" yaml:"backoffLimitPerIndex,omitempty"`
+		Suspend               *bool           `json:"suspend,omitempty" yaml:"suspend,omitempty"`
+		Template              PodTemplateSpec `json:"template" yaml:"template"`
+	}
+
+	PodTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+		Spec     PodSpec    `json:"spec" yaml:"spec"`
+	}
+
+	PodSpec struct {
+		RestartPolicy         string            `json:"restartPolicy,omitempty" yaml:"restartPolicy,omitempty"`
+		ActiveDeadlineSeconds *int64            `json:"activeDeadlineSeconds,omitempty" yaml:"activeDeadlineSeconds,omitempty"`
+		NodeSelector          map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+		Volumes               []Volume          `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+		Containers            []Container       `json:"containers" yaml:"containers"`
+	}
+
+	Volume struct {
+		Name                  string                 `json:"name" yaml:"name"`
+		PersistentVolumeClaim *PersistentVolumeClaim `json:"persistentVolumeClaim,omitempty" yaml:"persistentVolumeClaim,omitempty"`
+	}
+
+	PersistentVolumeClaim struct {
+		ClaimName string `json:"claimName" yaml:"claimName"`
+	}
+
+	Container struct {
+		Name         string               `json:"name" yaml:"name"`
+		Image        string               `json:"image" yaml:"image"`
+		Command      []string             `json:"command,omitempty" yaml:"command,omitempty"`
+		Args         []string             `json:"args,omitempty" yaml:"args,omitempty"`
+		Env          []EnvVar             `json:"env,omitempty" yaml:"env,omitempty"`
+		VolumeMounts []VolumeMount        `json:"volumeMounts,omitempty" yaml:"volumeMounts,omitempty"`
+		Resources    ResourceRequirements `json:"resources,omitempty" yaml:"resources,omitempty"`
+	}
+
+	EnvVar struct {
+		Name  string `json:"name" yaml:"name"`
+		Value string `json:"value" yaml:"value"`
+	}
+
+	VolumeMount struct {
+		Name      string `json:"name" yaml:"name"`
+		MountPath string `json:"mountPath" yaml:"mountPath"`
+	}
+
+	ResourceRequirements struct {
+		Requests map[string]string `json:"requests,omitempty" yaml:"requests,omitempty"`
+		Limits   map[string]string `json:"limits,omitempty" yaml:"limits,omitempty"`
+	}
+
+	// Resources is a parsed summary of resource quantities. CPU is in cores,
+	// memory in bytes and GPU in number of devices.
+	Resources struct {
+		CPU    float64
+		Memory float64
+		GPU    float64
+	}
+)
+
+// LoadManifest reads a workload payload from a JSON or YAML file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest file %s: %w", path, err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest parses a workload payload from JSON or YAML data
+func ParseManifest(data []byte) (*Manifest, error) {
+	// YAML is a superset of JSON, so this handles both
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %w", err)
+	}
+	if manifest.Workload.Kind != "" && manifest.Workload.Kind != "Job" {
+		return nil, fmt.Errorf("unsupported workload kind %q, only Job is supported", manifest.Workload.Kind)
+	}
+	return &manifest, nil
+}
+
+// PodResources returns the resources requested by a single pod of the job,
+// summed over all containers. Limits are used for containers that specify
+// no request, mirroring kubernetes defaulting.
+func (j *Job) PodResources() (Resources, error) {
+	var total Resources
+	for _, c := range j.Spec.Template.Spec.Containers {
+		for _, name := range []string{RESOURCE_CPU, RESOURCE_MEMORY, RESOURCE_GPU} {
+			q, ok := c.Resources.Requests[name]
+			if !ok {
+				q = c.Resources.Limits[name]
+			}
+			value, err := ParseQuantity(q)
+			if err != nil {
+				return Resources{}, fmt.Errorf("container %s: %s: %w", c.Name, name, err)
+			}
+			switch name {
+			case RESOURCE_CPU:
+				total.CPU += value
+			case RESOURCE_MEMORY:
+				total.Memory += value
+			case RESOURCE_GPU:
+				total.GPU += value
+			}
+		}
+	}
+	return total, nil
+}
+
+// Parallelism returns the maximum number of pods of the job running at once
+func (j *Job) Parallelism() int {
+	if j.Spec.Parallelism == nil {
+		return 1
+	}
+	return int(*j.Spec.Parallelism)
+}
+
+// Completions returns the number of pods of the job that must succeed
+func (j *Job) Completions() int {
+	if j.Spec.Completions == nil {
+		return j.Parallelism()
+	}
+	return int(*j.Spec.Completions)
+}
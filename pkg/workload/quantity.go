@@ -0,0 +1,54 @@
+package workload
+
+// Parsing of kubernetes resource quantities, e.g. "500m" CPU or "4Gi" memory.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var quantitySuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// binary suffixes must come before decimal ones, as "Mi" also ends in "i"
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"Ei", 1 << 60},
+	{"n", 1e-9},
+	{"u", 1e-6},
+	{"m", 1e-3},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+	{"E", 1e18},
+}
+
+// ParseQuantity parses a kubernetes resource quantity into a float. CPU
+// quantities result in cores and memory quantities in bytes.
+func ParseQuantity(q string) (float64, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return 0, nil
+	}
+	for _, s := range quantitySuffixes {
+		if number, ok := strings.CutSuffix(q, s.suffix); ok {
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid quantity %q", q)
+			}
+			return value * s.multiplier, nil
+		}
+	}
+	value, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", q)
+	}
+	return value, nil
+}
@@ -0,0 +1,189 @@
+package workload
+
+// Helpers to turn the stored spec of an existing workload into a new one.
+// Specs are handled as generic objects, so fields not modelled by Job survive.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RESTORE_FROM_ANNOTATION asks the platform to restore the pods of a new
+// workload from the last checkpoint of another, given as "namespace/name"
+const RESTORE_FROM_ANNOTATION = "cedana.ai/restore-from"
+
+// runtimeMetadataFields are set by kubernetes on a live object and rejected,
+// or misleading, when submitting a copy
+var runtimeMetadataFields = []string{
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"managedFields",
+	"selfLink",
+	"ownerReferences",
+	"finalizers",
+}
+
+// runtimeLabels are added by the job controller to tie pods to a specific job
+var runtimeLabels = []string{
+	"controller-uid",
+	"batch.kubernetes.io/controller-uid",
+	"job-name",
+	"batch.kubernetes.io/job-name",
+}
+
+// StripRuntimeFields removes the status and the fields set by kubernetes from
+// the spec of a live job, so it can be submitted again
+func StripRuntimeFields(job map[string]any) {
+	delete(job, "status")
+	if metadata, ok := job["metadata"].(map[string]any); ok {
+		for _, field := range runtimeMetadataFields {
+			delete(metadata, field)
+		}
+		stripLabels(metadata)
+		stripAnnotations(metadata)
+	}
+	spec, ok := job["spec"].(map[string]any)
+	if !ok {
+		return
+	}
+	// the selector is generated from the job's uid
+	delete(spec, "selector")
+	delete(spec, "manualSelector")
+	if template, ok := spec["template"].(map[string]any); ok {
+		if metadata, ok := template["metadata"].(map[string]any); ok {
+			stripLabels(metadata)
+		}
+	}
+}
+
+func stripLabels(metadata map[string]any) {
+	labels, ok := metadata["labels"].(map[string]any)
+	if !ok {
+		return
+	}
+	for _, label := range runtimeLabels {
+		delete(labels, label)
+	}
+	if len(labels) == 0 {
+		delete(metadata, "labels")
+	}
+}
+
+func stripAnnotations(metadata map[string]any) {
+	annotations, ok := metadata["annotations"].(map[string]any)
+	if !ok {
+		return
+	}
+	delete(annotations, RESTORE_FROM_ANNOTATION)
+	if len(annotations) == 0 {
+		delete(metadata, "annotations")
+	}
+}
+
+// SetAnnotation sets an annotation on a job. Annotation keys usually contain
+// dots, so they can't be set with SetOverride.
+func SetAnnotation(job map[string]any, key, value string) {
+	metadata, ok := job["metadata"].(map[string]any)
+	if !ok {
+		metadata = map[string]any{}
+		job["metadata"] = metadata
+	}
+	annotations, ok := metadata["annotations"].(map[string]any)
+	if !ok {
+		annotations = map[string]any{}
+		metadata["annotations"] = annotations
+	}
+	annotations[key] = value
+}
+
+// SetOverride applies an override of the form "path=value" to an object. The
+// path is dot-separated, with [N] to index lists, e.g.
+// "spec.template.spec.containers[0].image=gromacs:2024". The value is parsed
+// as YAML, so numbers and booleans keep their type.
+func SetOverride(obj map[string]any, override string) error {
+	path, raw, ok := strings.Cut(override, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("invalid override %q, must be path=value", override)
+	}
+	var value any
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+
+	keys, err := parsePath(path)
+	if err != nil {
+		return fmt.Errorf("invalid override %q: %w", override, err)
+	}
+	if err := setPath(obj, keys, value); err != nil {
+		return fmt.Errorf("invalid override %q: %w", override, err)
+	}
+	return nil
+}
+
+// parsePath splits a path into map keys (strings) and list indices (ints)
+func parsePath(path string) ([]any, error) {
+	var keys []any
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("empty key in path %q", path)
+		}
+		keys = append(keys, name)
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(index)
+			if !ok || err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index in %q", part)
+			}
+			keys = append(keys, n)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return keys, nil
+}
+
+// setPath sets value at keys in obj, creating intermediate maps as needed.
+// Lists are never grown, so indices must exist.
+func setPath(obj any, keys []any, value any) error {
+	switch key := keys[0].(type) {
+	case string:
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not in an object", key)
+		}
+		if len(keys) == 1 {
+			m[key] = value
+			return nil
+		}
+		next, ok := m[key]
+		if !ok || next == nil {
+			if _, isIndex := keys[1].(int); isIndex {
+				return fmt.Errorf("%s is not a list", key)
+			}
+			next = map[string]any{}
+			m[key] = next
+		}
+		return setPath(next, keys[1:], value)
+	case int:
+		list, ok := obj.([]any)
+		if !ok {
+			return fmt.Errorf("index %d is not in a list", key)
+		}
+		if key >= len(list) {
+			return fmt.Errorf("index %d out of range, list has %d items", key, len(list))
+		}
+		if len(keys) == 1 {
+			list[key] = value
+			return nil
+		}
+		return setPath(list[key], keys[1:], value)
+	}
+	return nil
+}
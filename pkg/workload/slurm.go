@@ -0,0 +1,534 @@
+package workload
+
+// Conversion of Slurm batch scripts into workload payloads. The #SBATCH
+// directives of a single-node job are mapped onto a Job running the script in
+// a container, with job arrays becoming indexed Jobs.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SLURM_SHELL runs the batch script in the container
+	SLURM_SHELL = "/bin/bash"
+
+	MAX_NAME_LENGTH = 63
+
+	// MAX_ARRAY_SIZE bounds job array task ids like the MaxArraySize default of slurm
+	MAX_ARRAY_SIZE = 1001
+)
+
+// SlurmOptions are the settings of a converted workload that a batch script doesn't specify
+type SlurmOptions struct {
+	Image       string
+	ClusterName string
+	Namespace   string
+	// Queue is the kueue local queue, defaulting to the partition of the script
+	Queue string
+	// Name defaults to the job name of the script, or its file name
+	Name string
+}
+
+// slurmJob is what the directives of a batch script ask for
+type slurmJob struct {
+	name       string
+	partition  string
+	cpus       int
+	memory     string // kubernetes quantity
+	memPerCPU  string // slurm size, multiplied by cpus
+	gpus       int
+	timeLimit  int64 // seconds
+	arrayIDs   []int
+	arrayLimit int
+}
+
+// slurmIgnored are directives with no equivalent for a Job, which only affect
+// how slurm reports or accounts for the job
+var slurmIgnored = map[string]bool{
+	"output":       true,
+	"error":        true,
+	"mail-type":    true,
+	"mail-user":    true,
+	"account":      true,
+	"qos":          true,
+	"comment":      true,
+	"chdir":        true,
+	"export":       true,
+	"requeue":      true,
+	"no-requeue":   true,
+	"exclusive":    true,
+	"constraint":   true,
+	"reservation":  true,
+	"dependency":   true,
+	"begin":        true,
+	"open-mode":    true,
+	"hint":         true,
+	"mem-bind":     true,
+	"cpu-bind":     true,
+	"distribution": true,
+}
+
+// slurmShortOptions maps the short forms of directives to their long form
+var slurmShortOptions = map[string]string{
+	"J": "job-name",
+	"c": "cpus-per-task",
+	"t": "time",
+	"a": "array",
+	"p": "partition",
+	"n": "ntasks",
+	"N": "nodes",
+	"G": "gpus",
+	"o": "output",
+	"e": "error",
+	"A": "account",
+	"q": "qos",
+	"C": "constraint",
+	"D": "chdir",
+	"d": "dependency",
+	"w": "nodelist",
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ConvertSlurm converts a Slurm batch script into a workload payload. Directives
+// that can't be honoured are returned as warnings, rather than failing the
+// conversion.
+func ConvertSlurm(script []byte, path string, opts SlurmOptions) (*Manifest, []string, error) {
+	if opts.Image == "" {
+		return nil, nil, fmt.Errorf("an image to run the script in is required")
+	}
+
+	directives, err := slurmDirectives(script)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := slurmJob{cpus: 1}
+	var warnings []string
+	for _, d := range directives {
+		warning, err := job.apply(d[0], d[1])
+		if err != nil {
+			return nil, warnings, fmt.Errorf("#SBATCH --%s: %w", d[0], err)
+		}
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("#SBATCH --%s: %s", d[0], warning))
+		}
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = job.name
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	name = jobName(name)
+	if name == "" {
+		return nil, warnings, fmt.Errorf("no valid job name, set one with --name")
+	}
+
+	resources := ResourceRequirements{Requests: map[string]string{
+		RESOURCE_CPU: strconv.Itoa(job.cpus),
+	}}
+	if job.memPerCPU != "" {
+		memory, err := slurmMemory(job.memPerCPU, job.cpus)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("#SBATCH --mem-per-cpu: %w", err)
+		}
+		job.memory = memory
+	}
+	if job.memory != "" {
+		resources.Requests[RESOURCE_MEMORY] = job.memory
+	}
+	if job.gpus > 0 {
+		// GPUs can't be overcommitted, so kubernetes requires them as limits
+		resources.Limits = map[string]string{RESOURCE_GPU: strconv.Itoa(job.gpus)}
+	}
+
+	// the variables slurm sets that scripts commonly rely on
+	env := []EnvVar{
+		{Name: "SLURM_JOB_NAME", Value: name},
+		{Name: "SLURM_CPUS_PER_TASK", Value: strconv.Itoa(job.cpus)},
+		{Name: "SLURM_NTASKS", Value: "1"},
+		{Name: "SLURM_JOB_NUM_NODES", Value: "1"},
+	}
+
+	body := string(script)
+	noRetries := int32(0) // slurm does not retry failed jobs
+	manifest := &Manifest{
+		ClusterName: opts.ClusterName,
+		Workload: Job{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+			Metadata: ObjectMeta{
+				Name:      name,
+				Namespace: opts.Namespace,
+			},
+			Spec: JobSpec{
+				Template: PodTemplateSpec{
+					Spec: PodSpec{
+						RestartPolicy: "Never",
+					},
+				},
+			},
+		},
+	}
+	spec := &manifest.Workload.Spec
+
+	if len(job.arrayIDs) == 0 {
+		spec.BackoffLimit = &noRetries
+		if job.timeLimit > 0 {
+			spec.ActiveDeadlineSeconds = &job.timeLimit
+		}
+	} else {
+		completions := int32(len(job.arrayIDs))
+		parallelism := completions
+		if job.arrayLimit > 0 && int32(job.arrayLimit) < completions {
+			parallelism = int32(job.arrayLimit)
+		}
+		spec.CompletionMode = "Indexed"
+		spec.Completions = &completions
+		spec.Parallelism = &parallelism
+		// each array task fails on its own, without stopping the others
+		spec.BackoffLimitPerIndex = &noRetries
+
+		if job.timeLimit > 0 {
+			// the time limit applies to each array task, while the deadline
+			// of a job covers all of its pods, which run in waves of parallelism
+			spec.Template.Spec.ActiveDeadlineSeconds = &job.timeLimit
+			waves := int64((completions + parallelism - 1) / parallelism)
+			deadline := job.timeLimit * waves
+			spec.ActiveDeadlineSeconds = &deadline
+		}
+
+		// map the index of each pod onto its slurm array task id
+		ids := make([]string, len(job.arrayIDs))
+		minID, maxID := job.arrayIDs[0], job.arrayIDs[0]
+		for i, id := range job.arrayIDs {
+			ids[i] = strconv.Itoa(id)
+			minID, maxID = min(minID, id), max(maxID, id)
+		}
+		env = append(env,
+			EnvVar{Name: "SLURM_ARRAY_TASK_COUNT", Value: strconv.Itoa(len(ids))},
+			EnvVar{Name: "SLURM_ARRAY_TASK_MIN", Value: strconv.Itoa(minID)},
+			EnvVar{Name: "SLURM_ARRAY_TASK_MAX", Value: strconv.Itoa(maxID)},
+		)
+		body = fmt.Sprintf("task_ids=(%s)\nexport SLURM_ARRAY_TASK_ID=${task_ids[$JOB_COMPLETION_INDEX]}\n%s",
+			strings.Join(ids, " "), body)
+	}
+
+	queue := opts.Queue
+	if queue == "" {
+		queue = job.partition
+	}
+	if queue != "" {
+		manifest.Workload.Metadata.Labels = map[string]string{QUEUE_NAME_LABEL: queue}
+	}
+
+	spec.Template.Spec.Containers = []Container{{
+		Name:      name,
+		Image:     opts.Image,
+		Command:   []string{SLURM_SHELL, "-c", body},
+		Env:       env,
+		Resources: resources,
+	}}
+	return manifest, warnings, nil
+}
+
+// slurmDirectives returns the options of the #SBATCH lines of a script, as
+// (name, value) pairs with names in their long form. Like sbatch, directives
+// are only read until the first command of the script.
+func slurmDirectives(script []byte) ([][2]string, error) {
+	var directives [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(script))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if !strings.HasPrefix(text, "#") {
+			break
+		}
+		rest, ok := strings.CutPrefix(text, "#SBATCH")
+		if !ok {
+			continue
+		}
+		rest, _, _ = strings.Cut(rest, " #") // trailing comment
+		fields := strings.Fields(rest)
+		for i := 0; i < len(fields); i++ {
+			name, value, err := slurmOption(fields, &i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			directives = append(directives, [2]string{name, value})
+		}
+	}
+	return directives, scanner.Err()
+}
+
+// slurmOption parses the option at fields[*i], consuming its value from the
+// next field if it isn't attached
+func slurmOption(fields []string, i *int) (name, value string, err error) {
+	field := fields[*i]
+	switch {
+	case strings.HasPrefix(field, "--"):
+		name, value, ok := strings.Cut(field[2:], "=")
+		if !ok && *i+1 < len(fields) && !strings.HasPrefix(fields[*i+1], "-") {
+			*i++
+			value = fields[*i]
+		}
+		return name, value, nil
+	case strings.HasPrefix(field, "-") && len(field) >= 2:
+		long, ok := slurmShortOptions[field[1:2]]
+		if !ok {
+			return field[1:2], field[2:], nil
+		}
+		value := strings.TrimPrefix(field[2:], "=")
+		if value == "" && *i+1 < len(fields) && !strings.HasPrefix(fields[*i+1], "-") {
+			*i++
+			value = fields[*i]
+		}
+		return long, value, nil
+	default:
+		return "", "", fmt.Errorf("unexpected %q, expected an option", field)
+	}
+}
+
+// apply applies a directive, returning a warning if it is not fully supported
+func (j *slurmJob) apply(name, value string) (string, error) {
+	switch name {
+	case "job-name":
+		j.name = value
+	case "partition":
+		j.partition = value
+	case "cpus-per-task":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid number of CPUs %q", value)
+		}
+		j.cpus = n
+	case "mem":
+		if strings.TrimRight(value, "KMGTkmgt") == "0" {
+			return "requesting all memory of a node is not supported, no memory request set", nil
+		}
+		memory, err := slurmMemory(value, 1)
+		if err != nil {
+			return "", err
+		}
+		j.memory = memory
+	case "mem-per-cpu":
+		j.memPerCPU = value
+	case "time":
+		seconds, err := slurmTime(value)
+		if err != nil {
+			return "", err
+		}
+		j.timeLimit = seconds
+	case "array":
+		ids, limit, err := slurmArray(value)
+		if err != nil {
+			return "", err
+		}
+		j.arrayIDs, j.arrayLimit = ids, limit
+	case "gres":
+		return j.applyGres(value)
+	case "gpus", "gpus-per-node", "gpus-per-task":
+		_, count, _ := cutLast(value, ":")
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid number of GPUs %q", value)
+		}
+		j.gpus = n
+		if strings.Contains(value, ":") {
+			return "GPU types are not supported, any GPU will be used", nil
+		}
+	case "ntasks", "nodes", "ntasks-per-node":
+		if value != "1" && value != "1-1" {
+			return "multi-task and multi-node jobs are not supported, the script runs as a single task on one node", nil
+		}
+	default:
+		if slurmIgnored[name] {
+			return "ignored, it has no equivalent for a workload", nil
+		}
+		return "unsupported directive, ignored", nil
+	}
+	return "", nil
+}
+
+// applyGres applies a --gres directive, of the form gpu[:type]:count, possibly
+// comma-separated with other resources
+func (j *slurmJob) applyGres(value string) (string, error) {
+	var warnings []string
+	for _, gres := range strings.Split(value, ",") {
+		resource, rest, _ := strings.Cut(gres, ":")
+		if resource != "gpu" {
+			warnings = append(warnings, fmt.Sprintf("generic resource %q is not supported", resource))
+			continue
+		}
+		count := "1"
+		if rest != "" {
+			var gpuType string
+			gpuType, count, _ = cutLast(rest, ":")
+			if _, err := strconv.Atoi(count); err != nil {
+				// gpu:type with no count
+				gpuType, count = rest, "1"
+			}
+			if gpuType != "" {
+				warnings = append(warnings, fmt.Sprintf("GPU type %q is not supported, any GPU will be used", gpuType))
+			}
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid number of GPUs %q", gres)
+		}
+		j.gpus = n
+	}
+	return strings.Join(warnings, "; "), nil
+}
+
+// slurmMemory converts a slurm memory size, in megabytes by default, into a
+// kubernetes quantity, multiplied by n
+func slurmMemory(value string, n int) (string, error) {
+	units := map[string]string{"K": "Ki", "M": "Mi", "G": "Gi", "T": "Ti"}
+	unit := "Mi"
+	number := value
+	if len(value) > 0 {
+		if u, ok := units[strings.ToUpper(value[len(value)-1:])]; ok {
+			unit = u
+			number = value[:len(value)-1]
+		}
+	}
+	size, err := strconv.Atoi(number)
+	if err != nil || size < 0 {
+		return "", fmt.Errorf("invalid memory size %q", value)
+	}
+	return strconv.Itoa(size*n) + unit, nil
+}
+
+// slurmTime converts a slurm time limit into seconds. Accepted formats are
+// "minutes", "minutes:seconds", "hours:minutes:seconds", "days-hours",
+// "days-hours:minutes" and "days-hours:minutes:seconds".
+func slurmTime(value string) (int64, error) {
+	if value == "" || value == "UNLIMITED" || value == "INFINITE" {
+		return 0, nil
+	}
+	invalid := fmt.Errorf("invalid time limit %q", value)
+
+	var days int64
+	rest := value
+	if d, after, ok := strings.Cut(value, "-"); ok {
+		n, err := strconv.ParseInt(d, 10, 64)
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		days, rest = n, after
+	}
+
+	var parts []int64
+	for _, part := range strings.Split(rest, ":") {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		parts = append(parts, n)
+	}
+
+	var hours, minutes, seconds int64
+	switch {
+	case days > 0 || strings.Contains(value, "-"):
+		// days-hours[:minutes[:seconds]]
+		if len(parts) > 3 {
+			return 0, invalid
+		}
+		hours = parts[0]
+		if len(parts) > 1 {
+			minutes = parts[1]
+		}
+		if len(parts) > 2 {
+			seconds = parts[2]
+		}
+	case len(parts) == 1:
+		minutes = parts[0]
+	case len(parts) == 2:
+		minutes, seconds = parts[0], parts[1]
+	case len(parts) == 3:
+		hours, minutes, seconds = parts[0], parts[1], parts[2]
+	default:
+		return 0, invalid
+	}
+	return ((days*24+hours)*60+minutes)*60 + seconds, nil
+}
+
+// slurmArray parses a job array spec, e.g. "0-15", "1-9:2", "1,3,5" or
+// "0-99%10", into its task ids and the limit of tasks running at once
+func slurmArray(value string) ([]int, int, error) {
+	invalid := fmt.Errorf("invalid job array %q", value)
+
+	spec, limitStr, hasLimit := strings.Cut(value, "%")
+	limit := 0
+	if hasLimit {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			return nil, 0, invalid
+		}
+		limit = n
+	}
+
+	var ids []int
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(spec, ",") {
+		rangeStr, stepStr, hasStep := strings.Cut(item, ":")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return nil, 0, invalid
+			}
+			step = n
+		}
+		startStr, endStr, isRange := strings.Cut(rangeStr, "-")
+		start, err := strconv.Atoi(startStr)
+		if err != nil || start < 0 {
+			return nil, 0, invalid
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(endStr); err != nil || end < start {
+				return nil, 0, invalid
+			}
+		}
+		if end >= MAX_ARRAY_SIZE {
+			return nil, 0, fmt.Errorf("job array %q has task ids above the maximum of %d", value, MAX_ARRAY_SIZE-1)
+		}
+		for id := start; id <= end; id += step {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, limit, nil
+}
+
+// jobName turns a slurm job name into a valid kubernetes name
+func jobName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > MAX_NAME_LENGTH {
+		name = name[:MAX_NAME_LENGTH]
+	}
+	return strings.Trim(name, "-")
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}